@@ -0,0 +1,108 @@
+package erz
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+)
+
+// BatchResponse is the envelope of bulk endpoints that report the outcome of
+// every item separately. Success is true only if all items succeeded.
+type BatchResponse struct {
+	XMLName   xml.Name          `json:"-" xml:"batch_response"`
+	Success   bool              `json:"success" xml:"success"`
+	Results   []BatchResult     `json:"results" xml:"result"`
+	Meta      *HTTPResponseMeta `json:"meta,omitempty" xml:"meta,omitempty"`
+	Timestamp time.Time         `json:"timestamp,omitempty" xml:"timestamp,omitempty"`
+	RequestID string            `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	TraceID   string            `json:"trace_id,omitempty" xml:"trace_id,omitempty"`
+}
+
+// BatchResult is the outcome of one item: its data on success, or its error
+// with the status the item would have had on its own.
+type BatchResult struct {
+	Index  int                `json:"index" xml:"index"`
+	ID     string             `json:"id,omitempty" xml:"id,omitempty"`
+	Status int                `json:"status" xml:"status"`
+	Data   interface{}        `json:"data,omitempty" xml:"data,omitempty"`
+	Error  *HTTPErrorResponse `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+func BatchSuccess(index int, data interface{}) BatchResult {
+	return BatchResult{
+		Index:  index,
+		Status: http.StatusOK,
+		Data:   data,
+	}
+}
+
+// BatchFailure converts err like ToHTTPResponse, so options such as Redact and
+// Language apply to every item.
+func BatchFailure(index int, err Error, options *HTTPOptions) BatchResult {
+	err = orNilPassed(err)
+
+	return BatchResult{
+		Index:  index,
+		Status: err.HTTPStatus(),
+		Error:  err.ToHTTPResponse(options).Error,
+	}
+}
+
+func (r BatchResult) WithID(id string) BatchResult {
+	r.ID = id
+	return r
+}
+
+func CreateBatchResponse(results []BatchResult, options *HTTPOptions) *BatchResponse {
+	if options == nil {
+		options = DefaultHTTPOptions()
+	}
+
+	response := &BatchResponse{
+		Success: true,
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			response.Success = false
+			break
+		}
+	}
+
+	if options.IncludeTimestamp {
+		response.Timestamp = now().UTC()
+	}
+
+	response.RequestID = options.RequestID
+	response.TraceID = options.TraceID
+
+	if version := options.metaVersion(); version != "" {
+		response.Meta = &HTTPResponseMeta{Version: version}
+	}
+
+	return response
+}
+
+// WriteBatchResponse writes the results with status 200 if every item
+// succeeded and 207 Multi-Status otherwise.
+func WriteBatchResponse(w http.ResponseWriter, results []BatchResult, options *HTTPOptions) error {
+	if options == nil {
+		options = DefaultHTTPOptions()
+	}
+
+	response := CreateBatchResponse(results, options)
+	body, contentType, err := options.Encode(response)
+	if err != nil {
+		return err
+	}
+
+	status := http.StatusOK
+	if !response.Success {
+		status = http.StatusMultiStatus
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, writeErr := w.Write(body)
+	return writeErr
+}
@@ -0,0 +1,71 @@
+package erz
+
+import "google.golang.org/grpc/codes"
+
+// ErBuilder describes a fully populated error for Build. Zero fields are
+// left unset.
+type ErBuilder struct {
+	Code             ErrorCode
+	Message          string
+	Detail           string
+	Hint             string
+	PublicMessage    string
+	MessageKey       string
+	Operation        string
+	ValidationErrors []ValidationError
+	Cause            error
+	Metadata         map[string]any
+	HTTPStatus       int
+	GRPCCode         codes.Code
+	// StackTrace always captures the stack instead of following the
+	// sampling configured with SetStackTraceSampler.
+	StackTrace bool
+}
+
+// Build creates an error from b in one call. Without a code it uses
+// CodeValidation when validation errors are set and CodeUnknown otherwise.
+func Build(b ErBuilder) Error {
+	code := b.Code
+	if code == "" {
+		code = CodeUnknown
+		if len(b.ValidationErrors) > 0 {
+			code = CodeValidation
+		}
+	}
+
+	err := &Er{
+		errCode:       code,
+		message:       b.Message,
+		detail:        b.Detail,
+		hint:          b.Hint,
+		publicMessage: b.PublicMessage,
+		messageKey:    b.MessageKey,
+		operation:     b.Operation,
+		httpStatus:    b.HTTPStatus,
+		grpcCode:      b.GRPCCode,
+	}
+
+	if len(b.ValidationErrors) > 0 {
+		err.validationErrors = make([]ValidationError, len(b.ValidationErrors))
+		copy(err.validationErrors, b.ValidationErrors)
+	}
+
+	if b.Cause != nil {
+		err.wrapped = []error{b.Cause}
+	}
+
+	if len(b.Metadata) > 0 {
+		err.metadata = make(map[string]any, len(b.Metadata))
+		for k, v := range b.Metadata {
+			err.metadata[k] = v
+		}
+	}
+
+	if b.StackTrace {
+		err.lazyStack = captureLazyStack(2)
+	} else {
+		err.lazyStack = sampledStack(2)
+	}
+
+	return observe(err)
+}
@@ -0,0 +1,51 @@
+package erz
+
+import (
+	"encoding/json"
+	"google.golang.org/grpc/codes"
+	"net/http"
+)
+
+type CodeInfo struct {
+	Code          ErrorCode  `json:"code"`
+	HTTPStatus    int        `json:"http_status"`
+	GRPCCode      codes.Code `json:"grpc_code"`
+	GRPCCodeName  string     `json:"grpc_code_name"`
+	PublicMessage string     `json:"public_message"`
+}
+
+// CodeCatalog lists the built-in codes followed by the registered custom codes
+// with the HTTP status, gRPC code and public message they map to.
+func CodeCatalog() []CodeInfo {
+	allCodes := append(append([]ErrorCode{}, builtinCodes...), registeredCodeList()...)
+
+	catalog := make([]CodeInfo, 0, len(allCodes))
+	for _, code := range allCodes {
+		httpStatus, ok := httpStatusForCode(code)
+		if !ok {
+			httpStatus = http.StatusInternalServerError
+		}
+		grpcCode, ok := grpcCodeForCode(code)
+		if !ok {
+			grpcCode = codes.Unknown
+		}
+
+		catalog = append(
+			catalog, CodeInfo{
+				Code:          code,
+				HTTPStatus:    httpStatus,
+				GRPCCode:      grpcCode,
+				GRPCCodeName:  grpcCode.String(),
+				PublicMessage: DefaultPublicMessage(code),
+			},
+		)
+	}
+
+	return catalog
+}
+
+// CatalogHandler serves CodeCatalog as JSON, e.g. for generating client SDKs.
+func CatalogHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", MediaTypeJSON)
+	_ = json.NewEncoder(w).Encode(CodeCatalog())
+}
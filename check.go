@@ -1,8 +1,20 @@
 package erz
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
 
 func IsCode(err error, code ErrorCode) bool {
+	// Most checks are on errors returned directly by erz, which don't need
+	// the chain walk of errors.As.
+	if e, ok := err.(*Er); ok {
+		return e.errCode == code
+	}
+
 	var erzErr Error
 	if errors.As(err, &erzErr) {
 		return erzErr.Code() == code
@@ -10,10 +22,103 @@ func IsCode(err error, code ErrorCode) bool {
 	return false
 }
 
+func IsAnyCode(err error, codes ...ErrorCode) bool {
+	code, ok := Code(err)
+	if !ok {
+		return false
+	}
+
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func Code(err error) (ErrorCode, bool) {
+	if e, ok := err.(*Er); ok {
+		return e.errCode, true
+	}
+
+	var erzErr Error
+	if errors.As(err, &erzErr) {
+		return erzErr.Code(), true
+	}
+	return "", false
+}
+
+// CodeOf returns the code FromError would assign to err, without creating an
+// error: no stack is captured and the error observer is not called. It returns
+// "" for a nil err.
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	if code, ok := Code(err); ok {
+		return code
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return statusErrorCode(st)
+	}
+	return CodeUnknown
+}
+
+// HTTPStatusOf returns the HTTP status FromError(err).HTTPStatus() would
+// report, without creating an error.
+func HTTPStatusOf(err error) int {
+	var erzErr Error
+	if errors.As(err, &erzErr) {
+		return erzErr.HTTPStatus()
+	}
+	if status, ok := httpStatusForCode(CodeOf(err)); ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
 func IsNotFound(err error) bool {
 	return IsCode(err, CodeNotFound)
 }
 
+// ClassifyNotFound reports whether anything in the chain of err signals a
+// missing resource, whichever transport it came from: erz errors with
+// CodeNotFound or a 404 status (including errors built from HTTP responses)
+// and gRPC status errors with codes.NotFound, also when wrapped by other
+// errors such as *url.Error.
+func ClassifyNotFound(err error) bool {
+	return walkChain(
+		err, func(err error) bool {
+			switch e := err.(type) {
+			case Error:
+				return e.Code() == CodeNotFound || e.HTTPStatus() == http.StatusNotFound
+			case interface{ GRPCStatus() *status.Status }:
+				return e.GRPCStatus().Code() == codes.NotFound
+			}
+			return false
+		},
+	)
+}
+
+// IsServerFault reports whether err is an erz error for which
+// Error.IsServerFault is true. Errors that are not erz errors are not
+// classified and return false.
+func IsServerFault(err error) bool {
+	var erzErr Error
+	if errors.As(err, &erzErr) {
+		return erzErr.IsServerFault()
+	}
+	return false
+}
+
 func IsInvalidInput(err error) bool {
 	return IsCode(err, CodeInvalidInput)
 }
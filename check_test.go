@@ -0,0 +1,47 @@
+package erz_test
+
+import (
+	"fmt"
+	"github.com/intezya/erz"
+	"testing"
+)
+
+var benchBool bool
+
+func BenchmarkIsCode(b *testing.B) {
+	err := erz.NotFound("user")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchBool = erz.IsCode(err, erz.CodeNotFound)
+	}
+}
+
+func BenchmarkIsCodeWrapped(b *testing.B) {
+	err := fmt.Errorf("load user: %w", erz.NotFound("user"))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchBool = erz.IsCode(err, erz.CodeNotFound)
+	}
+}
+
+func TestIsCode(t *testing.T) {
+	direct := erz.NotFound("user")
+	wrapped := fmt.Errorf("load user: %w", direct)
+
+	for _, err := range []error{direct, wrapped} {
+		if !erz.IsCode(err, erz.CodeNotFound) {
+			t.Errorf("IsCode(%v, CodeNotFound) = false", err)
+		}
+		if erz.IsCode(err, erz.CodeInternal) {
+			t.Errorf("IsCode(%v, CodeInternal) = true", err)
+		}
+		if code, ok := erz.Code(err); !ok || code != erz.CodeNotFound {
+			t.Errorf("Code(%v) = %q, %v", err, code, ok)
+		}
+	}
+	if erz.IsCode(fmt.Errorf("plain"), erz.CodeNotFound) {
+		t.Error("IsCode matched a non-erz error")
+	}
+}
@@ -0,0 +1,25 @@
+package erz
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var clock atomic.Pointer[func() time.Time]
+
+// SetClock replaces the time source used for response timestamps, e.g. to
+// freeze time in tests. Passing nil restores time.Now.
+func SetClock(now func() time.Time) {
+	if now == nil {
+		clock.Store(nil)
+		return
+	}
+	clock.Store(&now)
+}
+
+func now() time.Time {
+	if c := clock.Load(); c != nil {
+		return (*c)()
+	}
+	return time.Now()
+}
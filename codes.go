@@ -14,4 +14,71 @@ const (
 	CodeTimeout           ErrorCode = "TIMEOUT"
 	CodeResourceExhausted ErrorCode = "RESOURCE_EXHAUSTED"
 	CodeValidation        ErrorCode = "VALIDATION"
+	CodeCanceled          ErrorCode = "CANCELED"
+	CodeConflict          ErrorCode = "CONFLICT"
+	CodeBadGateway        ErrorCode = "BAD_GATEWAY"
+	CodeGatewayTimeout    ErrorCode = "GATEWAY_TIMEOUT"
+	CodeNotImplemented    ErrorCode = "NOT_IMPLEMENTED"
+	CodeMethodNotAllowed  ErrorCode = "METHOD_NOT_ALLOWED"
+	CodeGone              ErrorCode = "GONE"
 )
+
+var builtinCodes = []ErrorCode{
+	CodeUnknown,
+	CodeInvalidInput,
+	CodeNotFound,
+	CodeAlreadyExists,
+	CodePermissionDenied,
+	CodeUnauthenticated,
+	CodeInternal,
+	CodeUnavailable,
+	CodeTimeout,
+	CodeResourceExhausted,
+	CodeValidation,
+	CodeCanceled,
+	CodeConflict,
+	CodeBadGateway,
+	CodeGatewayTimeout,
+	CodeNotImplemented,
+	CodeMethodNotAllowed,
+	CodeGone,
+}
+
+var defaultPublicMessages = map[ErrorCode]string{
+	CodeUnknown:           "An unknown error occurred",
+	CodeInvalidInput:      "The request contains invalid input",
+	CodeNotFound:          "The requested resource was not found",
+	CodeAlreadyExists:     "The resource already exists",
+	CodePermissionDenied:  "You do not have permission to perform this action",
+	CodeUnauthenticated:   "Authentication is required",
+	CodeInternal:          "An internal error occurred",
+	CodeUnavailable:       "The service is temporarily unavailable",
+	CodeTimeout:           "The request timed out",
+	CodeResourceExhausted: "Too many requests, please try again later",
+	CodeValidation:        "The request failed validation",
+	CodeCanceled:          "The request was canceled",
+	CodeConflict:          "The request conflicts with the current state of the resource",
+	CodeBadGateway:        "An upstream service returned an invalid response",
+	CodeGatewayTimeout:    "An upstream service did not respond in time",
+	CodeNotImplemented:    "This operation is not implemented",
+	CodeMethodNotAllowed:  "The request method is not allowed for this resource",
+	CodeGone:              "The requested resource is no longer available",
+}
+
+func DefaultPublicMessage(code ErrorCode) string {
+	if msg, ok := publicMessageForCode(code); ok {
+		return msg
+	}
+	return defaultPublicMessages[CodeUnknown]
+}
+
+func publicMessageForCode(code ErrorCode) (string, bool) {
+	if msg, ok := defaultPublicMessages[code]; ok {
+		return msg, true
+	}
+
+	if mapping, ok := registeredCode(code); ok && mapping.PublicMessage != "" {
+		return mapping.PublicMessage, true
+	}
+	return "", false
+}
@@ -0,0 +1,79 @@
+package erz
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ContextIDs are the correlation IDs a ContextExtractor finds in a context.
+type ContextIDs struct {
+	RequestID string
+	TraceID   string
+	SpanID    string
+}
+
+type ContextExtractor func(ctx context.Context) ContextIDs
+
+var contextExtractor atomic.Pointer[ContextExtractor]
+
+// SetContextExtractor registers the function WithContext uses to read
+// correlation IDs from a context, e.g. erzotel.ExtractContextIDs for
+// OpenTelemetry spans. Passing nil removes it.
+func SetContextExtractor(extractor ContextExtractor) {
+	if extractor == nil {
+		contextExtractor.Store(nil)
+		return
+	}
+	contextExtractor.Store(&extractor)
+}
+
+// WithContext attaches the request and trace IDs found in ctx by the
+// registered ContextExtractor. The span ID is stored as "span_id" metadata.
+// IDs already set on the error are kept, and without an extractor or IDs the
+// error is returned unchanged.
+func (e *Er) WithContext(ctx context.Context) Error {
+	extractor := contextExtractor.Load()
+	if extractor == nil || ctx == nil {
+		return e
+	}
+
+	ids := (*extractor)(ctx)
+	if ids == (ContextIDs{}) {
+		return e
+	}
+
+	newErr := e.copy()
+	if newErr.requestID == "" {
+		newErr.requestID = ids.RequestID
+	}
+	if newErr.traceID == "" {
+		newErr.traceID = ids.TraceID
+	}
+	if ids.SpanID != "" {
+		newErr.metadata = make(map[string]any, len(e.metadata)+1)
+		for k, v := range e.metadata {
+			newErr.metadata[k] = v
+		}
+		newErr.metadata["span_id"] = ids.SpanID
+	}
+	return newErr
+}
+
+type httpOptionsContextKey struct{}
+
+// ContextWithOptions returns a copy of ctx carrying opts, for middleware that
+// configures error responses per request.
+func ContextWithOptions(ctx context.Context, opts *HTTPOptions) context.Context {
+	return context.WithValue(ctx, httpOptionsContextKey{}, opts)
+}
+
+// OptionsFromContext returns the options stored by ContextWithOptions, or
+// DefaultHTTPOptions if ctx carries none.
+func OptionsFromContext(ctx context.Context) *HTTPOptions {
+	if ctx != nil {
+		if opts, ok := ctx.Value(httpOptionsContextKey{}).(*HTTPOptions); ok && opts != nil {
+			return opts
+		}
+	}
+	return DefaultHTTPOptions()
+}
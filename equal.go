@@ -0,0 +1,32 @@
+package erz
+
+import "reflect"
+
+// Equal reports whether a and b have the same code, message, detail, public
+// message and validation errors. Stack traces, wrapped errors, metadata and
+// transport overrides are ignored, which makes it suitable for test
+// assertions.
+func Equal(a, b Error) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if a.Code() != b.Code() ||
+		a.GetMessage() != b.GetMessage() ||
+		a.GetDetail() != b.GetDetail() ||
+		a.GetPublicMessage() != b.GetPublicMessage() {
+		return false
+	}
+
+	aValidationErrors, bValidationErrors := a.GetValidationErrors(), b.GetValidationErrors()
+	if len(aValidationErrors) != len(bValidationErrors) {
+		return false
+	}
+	for i := range aValidationErrors {
+		if !reflect.DeepEqual(aValidationErrors[i], bValidationErrors[i]) {
+			return false
+		}
+	}
+
+	return true
+}
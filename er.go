@@ -1,27 +1,112 @@
 package erz
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
 type Er struct {
 	errCode          ErrorCode
+	reason           string
 	message          string
 	detail           string
+	hint             string
+	messageKey       string
+	publicMessage    string
 	wrapped          []error
 	validationErrors []ValidationError
+	quotaViolations  []QuotaViolation
+	preconditions    []PreconditionViolation
 	stackTrace       []StackFrame
+	lazyStack        *lazyStack
+	fullStack        string
+	metadata         map[string]any
+	httpStatus       int
+	grpcCode         codes.Code
+	cacheControl     string
+	allowedMethods   []string
+	requestID        string
+	traceID          string
+	timestamp        time.Time
+	operation        string
+	statusCache      *statusCache
 }
 
 func (e *Er) erz() {}
 
+// Error returns the message, or the code if there is none, followed by the
+// validation errors with their field paths, e.g.
+// "validation failed: user.email: is required".
 func (e *Er) Error() string {
-	if e.message != "" {
-		return e.message
+	if e == nil {
+		return errNilPassed().Error()
+	}
+
+	message := e.message
+	if message == "" {
+		message = string(e.errCode)
+	}
+	if len(e.validationErrors) == 0 {
+		return message
+	}
+
+	var b strings.Builder
+	b.WriteString(message)
+	for i, ve := range e.validationErrors {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		if ve.Field != "" {
+			b.WriteString(ve.Field)
+			b.WriteString(": ")
+		}
+		b.WriteString(ve.Message)
 	}
-	return string(e.errCode)
+	return b.String()
 }
 
 func (e *Er) Code() ErrorCode {
 	return e.errCode
 }
 
+func (e *Er) Timeout() bool {
+	return e.errCode == CodeTimeout
+}
+
+func (e *Er) Temporary() bool {
+	switch e.errCode {
+	case CodeUnavailable, CodeResourceExhausted, CodeTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsServerFault reports whether the error means a backend is failing rather
+// than the client sending a bad request, e.g. to decide whether it should trip
+// a circuit breaker. Codes without a fixed classification count as server
+// faults when they map to a 5xx status.
+func (e *Er) IsServerFault() bool {
+	switch e.errCode {
+	case CodeInternal, CodeUnknown, CodeUnavailable, CodeTimeout, CodeResourceExhausted,
+		CodeBadGateway, CodeGatewayTimeout:
+		return true
+	case CodeInvalidInput, CodeValidation, CodeNotFound, CodeGone, CodeAlreadyExists, CodeConflict,
+		CodePermissionDenied, CodeUnauthenticated, CodeCanceled, CodeNotImplemented, CodeMethodNotAllowed:
+		return false
+	}
+	return e.HTTPStatus() >= http.StatusInternalServerError
+}
+
 func (e *Er) GetMessage() string {
 	return e.message
 }
@@ -30,47 +115,363 @@ func (e *Er) GetDetail() string {
 	return e.detail
 }
 
+func (e *Er) GetReason() string {
+	return e.reason
+}
+
+func (e *Er) GetHint() string {
+	return e.hint
+}
+
+func (e *Er) GetPublicMessage() string {
+	return e.publicMessage
+}
+
+// PublicError returns the message that is safe to show to clients: the public
+// message if one was set, otherwise the default public message for the code.
+func (e *Er) PublicError() string {
+	if e.publicMessage != "" {
+		return e.publicMessage
+	}
+	return DefaultPublicMessage(e.errCode)
+}
+
+func (e *Er) GetMessageKey() string {
+	return e.messageKey
+}
+
 func (e *Er) GetWrapped() []error {
 	return e.wrapped
 }
 
 func (e *Er) GetStackTrace() []StackFrame {
+	if e.stackTrace == nil && e.lazyStack != nil {
+		return e.lazyStack.resolve()
+	}
 	return e.stackTrace
 }
 
+func (e *Er) GetQuotaViolations() []QuotaViolation {
+	return e.quotaViolations
+}
+
+func (e *Er) GetPreconditionViolations() []PreconditionViolation {
+	return e.preconditions
+}
+
+func (e *Er) GetFullStack() string {
+	return e.fullStack
+}
+
+func (e *Er) GetCacheControl() string {
+	if e == nil {
+		return ""
+	}
+	return e.cacheControl
+}
+
+func (e *Er) GetAllowedMethods() []string {
+	if e == nil {
+		return nil
+	}
+	return e.allowedMethods
+}
+
+func (e *Er) GetRequestID() string {
+	return e.requestID
+}
+
+func (e *Er) GetTraceID() string {
+	return e.traceID
+}
+
+func (e *Er) GetTimestamp() time.Time {
+	return e.timestamp
+}
+
+func (e *Er) Operation() string {
+	return e.operation
+}
+
+func (e *Er) GetMetadata() map[string]any {
+	return e.metadata
+}
+
 func (e *Er) GetValidationErrors() []ValidationError {
 	return e.validationErrors
 }
 
+func (e *Er) FieldErrors() map[string][]string {
+	if len(e.validationErrors) == 0 {
+		return nil
+	}
+
+	fieldErrors := make(map[string][]string, len(e.validationErrors))
+	for _, ve := range e.validationErrors {
+		fieldErrors[ve.Field] = append(fieldErrors[ve.Field], ve.Message)
+	}
+	return fieldErrors
+}
+
 func (e *Er) WithDetail(detail string) Error {
 	newErr := e.copy()
 	newErr.detail = detail
 	return newErr
 }
 
+func (e *Er) WithDetailf(format string, args ...any) Error {
+	return e.WithDetail(fmt.Sprintf(format, args...))
+}
+
+// WithReason sets a machine-readable reason that refines the code, e.g.
+// "EMAIL_ALREADY_TAKEN" for CodeAlreadyExists. It is sent as the gRPC
+// ErrorInfo reason and as "reason" in HTTP responses.
+func (e *Er) WithReason(reason string) Error {
+	newErr := e.copy()
+	newErr.reason = reason
+	return newErr
+}
+
+// WithHint sets a client-facing suggestion of what to do next, e.g. "try
+// logging in again". Unlike the detail it is always sent to clients.
+func (e *Er) WithHint(hint string) Error {
+	newErr := e.copy()
+	newErr.hint = hint
+	return newErr
+}
+
+func (e *Er) WithPublicMessage(message string) Error {
+	newErr := e.copy()
+	newErr.publicMessage = message
+	return newErr
+}
+
+func (e *Er) WithPublicMessagef(format string, args ...any) Error {
+	return e.WithPublicMessage(fmt.Sprintf(format, args...))
+}
+
+// WithMessageKey sets a stable localization key (e.g. "error.user.not_found").
+// When resolving a localized message the key is looked up first, before
+// falling back to the default public message of the error code.
+func (e *Er) WithMessageKey(key string) Error {
+	newErr := e.copy()
+	newErr.messageKey = key
+	return newErr
+}
+
+// WithMetadata attaches a value under key for use by downstream code, see
+// Metadata. Metadata is not serialized into HTTP or gRPC responses.
+func (e *Er) WithMetadata(key string, value any) Error {
+	newErr := e.copy()
+	newErr.metadata = make(map[string]any, len(e.metadata)+1)
+	for k, v := range e.metadata {
+		newErr.metadata[k] = v
+	}
+	newErr.metadata[key] = value
+	return newErr
+}
+
+// WithHTTPStatus overrides the status returned by HTTPStatus for this error
+// only. FromHTTPStatus still maps statuses by code.
+func (e *Er) WithHTTPStatus(status int) Error {
+	newErr := e.copy()
+	newErr.httpStatus = status
+	return newErr
+}
+
+// WithGRPCCode overrides the code used by GRPCStatus for this error only.
+// FromGRPCStatus still maps gRPC codes by code.
+func (e *Er) WithGRPCCode(code codes.Code) Error {
+	newErr := e.copy()
+	newErr.grpcCode = code
+	return newErr
+}
+
+// WithRequestID attaches a request ID that travels with the error in HTTP
+// responses and gRPC status details.
+func (e *Er) WithRequestID(requestID string) Error {
+	newErr := e.copy()
+	newErr.requestID = requestID
+	return newErr
+}
+
+func (e *Er) WithTraceID(traceID string) Error {
+	newErr := e.copy()
+	newErr.traceID = traceID
+	return newErr
+}
+
+// WithOperation names the logical operation that failed, e.g. "CreateOrder",
+// for grouping in logs and metrics.
+func (e *Er) WithOperation(operation string) Error {
+	newErr := e.copy()
+	newErr.operation = operation
+	return newErr
+}
+
+// WithTimestamp records when the error occurred. HTTP responses use it instead
+// of the current time, which matters when errors are delivered asynchronously.
+func (e *Er) WithTimestamp(t time.Time) Error {
+	newErr := e.copy()
+	newErr.timestamp = t
+	return newErr
+}
+
+// WithCacheControl sets the Cache-Control header written by WriteHTTPError,
+// e.g. "no-store" for permission errors or "max-age=60" for lookups.
+func (e *Er) WithCacheControl(value string) Error {
+	newErr := e.copy()
+	newErr.cacheControl = value
+	return newErr
+}
+
+// WithAllowedMethods sets the methods reported in the Allow header of 405
+// responses written by WriteHTTPError.
+func (e *Er) WithAllowedMethods(methods ...string) Error {
+	newErr := e.copy()
+	newErr.allowedMethods = append([]string(nil), methods...)
+	return newErr
+}
+
+// WithWrapped appends err to the wrapped errors. Unwrap returns all of them,
+// in the order they were added.
 func (e *Er) WithWrapped(err error) Error {
 	newErr := e.copy()
 	newErr.wrapped = append(newErr.wrapped, err)
 	return newErr
 }
 
+// WithWrappedAll wraps all non-nil errs with a single copy of the error.
+func (e *Er) WithWrappedAll(errs ...error) Error {
+	newErr := e.copy()
+	for _, err := range errs {
+		if err != nil {
+			newErr.wrapped = append(newErr.wrapped, err)
+		}
+	}
+	return newErr
+}
+
+// WithCause replaces all wrapped errors with err, so Unwrap returns only the
+// new cause. Use WithWrapped to add supplementary errors instead.
+func (e *Er) WithCause(err error) Error {
+	newErr := e.copy()
+	newErr.wrapped = []error{err}
+	return newErr
+}
+
 func (e *Er) WithValidationErrors(errs ...ValidationError) Error {
 	newErr := e.copy()
-	newErr.validationErrors = append(newErr.validationErrors, errs...)
+	newErr.validationErrors = appendValidationErrors(newErr.validationErrors, errs...)
 	if newErr.errCode != CodeValidation {
 		newErr.errCode = CodeValidation
 	}
 	return newErr
 }
 
+// WithSortedValidationErrors orders the validation errors by field and then
+// message, so every transport serializes them deterministically.
+func (e *Er) WithSortedValidationErrors() Error {
+	newErr := e.copy()
+	newErr.validationErrors = SortValidationErrors(newErr.validationErrors)
+	return newErr
+}
+
+func (e *Er) WithValidationError(field, message string, value any) Error {
+	return e.WithValidationErrors(
+		ValidationError{
+			Field:   field,
+			Message: message,
+			Value:   value,
+		},
+	)
+}
+
+// WithField is a shorthand for WithValidationError. Chained calls accumulate
+// validation errors and switch the code to CodeValidation.
+func (e *Er) WithField(field, message string, value any) Error {
+	return e.WithValidationError(field, message, value)
+}
+
+func (e *Er) WithQuotaViolation(subject, description string) Error {
+	newErr := e.copy()
+	newErr.quotaViolations = append(
+		newErr.quotaViolations, QuotaViolation{
+			Subject:     subject,
+			Description: description,
+		},
+	)
+	if newErr.errCode != CodeResourceExhausted {
+		newErr.errCode = CodeResourceExhausted
+	}
+	return newErr
+}
+
+func (e *Er) WithPrecondition(violationType, subject, description string) Error {
+	newErr := e.copy()
+	newErr.preconditions = append(
+		newErr.preconditions, PreconditionViolation{
+			Type:        violationType,
+			Subject:     subject,
+			Description: description,
+		},
+	)
+	return newErr
+}
+
 func (e *Er) WithStackTrace() Error {
 	newErr := e.copy()
-	newErr.stackTrace = captureStackTrace(2)
+	newErr.stackTrace = nil
+	newErr.lazyStack = captureLazyStack(2)
+	return newErr
+}
+
+// WithStackTraceSkip captures the stack like WithStackTrace but skips the
+// given number of additional frames, so helpers can start the trace at their
+// caller.
+func (e *Er) WithStackTraceSkip(skip int) Error {
+	newErr := e.copy()
+	newErr.stackTrace = nil
+	newErr.lazyStack = captureLazyStack(2 + skip)
+	return newErr
+}
+
+// WithFullStack stores the complete goroutine stack as printed by
+// runtime/debug.Stack, in addition to the structured stack trace.
+func (e *Er) WithFullStack() Error {
+	newErr := e.copy()
+	newErr.fullStack = string(debug.Stack())
+	return newErr
+}
+
+func (e *Er) WithPanicStackTrace() Error {
+	newErr := e.copy()
+	newErr.stackTrace = capturePanicStackTrace(2)
+	newErr.lazyStack = nil
+	return newErr
+}
+
+// Redacted returns a copy without internal information: the message is
+// replaced by the public message and the detail, wrapped errors and stack
+// trace are dropped.
+func (e *Er) Redacted() Error {
+	return e.redacted("")
+}
+
+func (e *Er) redacted(lang string) *Er {
+	newErr := e.copy()
+	newErr.message = e.PublicErrorLang(lang)
+	newErr.detail = ""
+	newErr.wrapped = nil
+	newErr.stackTrace = nil
+	newErr.lazyStack = nil
+	newErr.fullStack = ""
 	return newErr
 }
 
 func (e *Er) copy() *Er {
 	newErr := *e
+	newErr.statusCache = &statusCache{}
 	if len(e.wrapped) > 0 {
 		newErr.wrapped = make([]error, len(e.wrapped))
 		copy(newErr.wrapped, e.wrapped)
@@ -79,6 +480,14 @@ func (e *Er) copy() *Er {
 		newErr.validationErrors = make([]ValidationError, len(e.validationErrors))
 		copy(newErr.validationErrors, e.validationErrors)
 	}
+	if len(e.quotaViolations) > 0 {
+		newErr.quotaViolations = make([]QuotaViolation, len(e.quotaViolations))
+		copy(newErr.quotaViolations, e.quotaViolations)
+	}
+	if len(e.preconditions) > 0 {
+		newErr.preconditions = make([]PreconditionViolation, len(e.preconditions))
+		copy(newErr.preconditions, e.preconditions)
+	}
 	if len(e.stackTrace) > 0 {
 		newErr.stackTrace = make([]StackFrame, len(e.stackTrace))
 		copy(newErr.stackTrace, e.stackTrace)
@@ -86,21 +495,119 @@ func (e *Er) copy() *Er {
 	return &newErr
 }
 
-func (e *Er) Unwrap() error {
-	if len(e.wrapped) > 0 {
-		return e.wrapped[0]
-	}
-	return nil
+func (e *Er) Unwrap() []error {
+	return e.wrapped
 }
 
-func New(errCode ErrorCode, message string) Error {
+func errNilPassed() *Er {
 	return &Er{
-		errCode:    errCode,
-		message:    message,
-		stackTrace: captureStackTrace(2),
+		errCode: CodeInternal,
+		message: "nil error passed",
+	}
+}
+
+// orNilPassed replaces a nil err, including a nil *Er stored in the
+// interface, with errNilPassed.
+func orNilPassed(err Error) Error {
+	if e, ok := err.(*Er); err == nil || (ok && e == nil) {
+		return errNilPassed()
 	}
+	return err
+}
+
+func New(errCode ErrorCode, message string) Error {
+	return observe(
+		&Er{
+			errCode:   errCode,
+			message:   message,
+			lazyStack: sampledStack(2),
+		},
+	)
+}
+
+// NewWithStackSkip creates an error with an unsampled stack trace that skips
+// the given number of frames above its caller.
+func NewWithStackSkip(errCode ErrorCode, message string, skip int) Error {
+	return observe(
+		&Er{
+			errCode:   errCode,
+			message:   message,
+			lazyStack: captureLazyStack(2 + skip),
+		},
+	)
 }
 
 func Wrap(err error, errCode ErrorCode, message string) Error {
-	return New(errCode, message).WithWrapped(err)
+	return observe(
+		&Er{
+			errCode:   errCode,
+			message:   message,
+			wrapped:   []error{err},
+			lazyStack: sampledStack(2),
+		},
+	)
+}
+
+// Errorf formats the message like fmt.Errorf. Errors passed with %w are
+// wrapped, so errors.Is and errors.As can find them.
+func Errorf(errCode ErrorCode, format string, args ...any) Error {
+	formatted := fmt.Errorf(format, args...)
+	return observe(
+		&Er{
+			errCode:   errCode,
+			message:   formatted.Error(),
+			wrapped:   formattedCauses(formatted),
+			lazyStack: sampledStack(2),
+		},
+	)
+}
+
+// Wrapf is like Wrap with a formatted message. Errors passed with %w are
+// wrapped after err.
+func Wrapf(err error, errCode ErrorCode, format string, args ...any) Error {
+	formatted := fmt.Errorf(format, args...)
+	return observe(
+		&Er{
+			errCode:   errCode,
+			message:   formatted.Error(),
+			wrapped:   append([]error{err}, formattedCauses(formatted)...),
+			lazyStack: sampledStack(2),
+		},
+	)
+}
+
+func formattedCauses(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	case interface{ Unwrap() []error }:
+		return u.Unwrap()
+	}
+	return nil
+}
+
+func FromError(err error) Error {
+	if err == nil {
+		return nil
+	}
+
+	var erzErr Error
+	if errors.As(err, &erzErr) {
+		return erzErr
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Wrap(err, CodeTimeout, err.Error())
+	case errors.Is(err, context.Canceled):
+		return Wrap(err, CodeCanceled, err.Error())
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return FromGRPCStatusWithDetails(st)
+	}
+
+	return Wrap(err, CodeUnknown, err.Error())
 }
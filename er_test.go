@@ -0,0 +1,89 @@
+package erz_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/intezya/erz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestWithDetailf(t *testing.T) {
+	base := erz.NotFound("user")
+	err := base.WithDetailf("lookup by id %d in %s", 42, "users")
+
+	if got, want := err.GetDetail(), "lookup by id 42 in users"; got != want {
+		t.Errorf("detail %q, want %q", got, want)
+	}
+	if err.Code() != erz.CodeNotFound {
+		t.Errorf("code %s, want %s", err.Code(), erz.CodeNotFound)
+	}
+	if base.GetDetail() != "" {
+		t.Errorf("WithDetailf modified the original error: %q", base.GetDetail())
+	}
+}
+
+func TestWithPublicMessagef(t *testing.T) {
+	err := erz.Conflict("order").WithPublicMessagef("Order %s cannot be cancelled", "o_42")
+
+	if got, want := err.PublicError(), "Order o_42 cannot be cancelled"; got != want {
+		t.Errorf("PublicError() = %q, want %q", got, want)
+	}
+	if err.Code() != erz.CodeConflict {
+		t.Errorf("code %s, want %s", err.Code(), erz.CodeConflict)
+	}
+	if got, want := err.Error(), "conflict: order"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestFromError(t *testing.T) {
+	original := erz.NotFound("user")
+	plain := errors.New("boom")
+
+	tests := []struct {
+		name string
+		err  error
+		want erz.ErrorCode
+	}{
+		{"erz error", original, erz.CodeNotFound},
+		{"wrapped erz error", fmt.Errorf("get user: %w", original), erz.CodeNotFound},
+		{"deadline exceeded", fmt.Errorf("call: %w", context.DeadlineExceeded), erz.CodeTimeout},
+		{"canceled", context.Canceled, erz.CodeCanceled},
+		{"grpc status", status.Error(codes.PermissionDenied, "no access"), erz.CodePermissionDenied},
+		{"erz grpc status", erz.Conflict("order").GRPCStatus().Err(), erz.CodeConflict},
+		{"plain error", plain, erz.CodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := erz.FromError(tt.err)
+			if err == nil {
+				t.Fatal("FromError returned nil")
+			}
+			if err.Code() != tt.want {
+				t.Errorf("code %s, want %s", err.Code(), tt.want)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		if err := erz.FromError(nil); err != nil {
+			t.Errorf("FromError(nil) = %v", err)
+		}
+	})
+	t.Run("erz error is returned as-is", func(t *testing.T) {
+		if err := erz.FromError(fmt.Errorf("get user: %w", original)); err != original {
+			t.Errorf("got %v, want the original error", err)
+		}
+	})
+	t.Run("causes stay matchable", func(t *testing.T) {
+		for _, cause := range []error{context.DeadlineExceeded, context.Canceled, plain} {
+			if err := erz.FromError(cause); !errors.Is(err, cause) {
+				t.Errorf("errors.Is(FromError(%v), %v) = false", cause, cause)
+			}
+		}
+	})
+}
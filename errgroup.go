@@ -0,0 +1,58 @@
+package erz
+
+import "sync"
+
+// ErrorGroup runs functions concurrently and aggregates all of their errors.
+// Unlike errgroup.Group it keeps every error instead of only the first one.
+// The zero value is ready to use.
+type ErrorGroup struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// Go runs f in a new goroutine. A panic in f is recovered and recorded as a
+// CodeInternal error.
+func (g *ErrorGroup) Go(f func() error) {
+	g.mu.Lock()
+	index := len(g.errs)
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		var err error
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = RecoverToError(recovered)
+			}
+			g.mu.Lock()
+			g.errs[index] = err
+			g.mu.Unlock()
+		}()
+
+		err = f()
+	}()
+}
+
+// Wait blocks until all functions have returned and combines their errors as
+// Join does, in the order the functions were started. The validation errors
+// of all inputs are merged into the result. It returns nil if no function
+// failed.
+func (g *ErrorGroup) Wait() Error {
+	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	joined := join(g.errs)
+	if joined == nil {
+		return nil
+	}
+
+	joined.validationErrors = mergeValidationErrors(g.errs)
+
+	return observe(joined)
+}
@@ -0,0 +1,83 @@
+package erz_test
+
+import (
+	"errors"
+	"github.com/intezya/erz"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestErrorGroupConcurrent is meant to run under the race detector: errors are
+// recorded by many goroutines while observers are registered and removed.
+func TestErrorGroupConcurrent(t *testing.T) {
+	const workers = 100
+
+	var observed atomic.Int64
+	stop := make(chan struct{})
+	var registering sync.WaitGroup
+	registering.Add(1)
+	go func() {
+		defer registering.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				remove := erz.AddErrorObserver(func(erz.Error) { observed.Add(1) })
+				erz.SetErrorObserver(func(erz.Error) { observed.Add(1) })
+				remove()
+			}
+		}
+	}()
+	defer erz.SetErrorObserver(nil)
+
+	var g erz.ErrorGroup
+	for i := 0; i < workers; i++ {
+		g.Go(
+			func() error {
+				switch i % 4 {
+				case 0:
+					return erz.FieldError("items["+strconv.Itoa(i)+"]", "is invalid", i)
+				case 1:
+					return erz.NotFound("item " + strconv.Itoa(i))
+				case 2:
+					panic("worker " + strconv.Itoa(i))
+				default:
+					return nil
+				}
+			},
+		)
+	}
+
+	err := g.Wait()
+	close(stop)
+	registering.Wait()
+
+	if err == nil {
+		t.Fatal("Wait returned nil")
+	}
+	if err.Code() != erz.CodeInternal {
+		t.Errorf("code %s, want %s", err.Code(), erz.CodeInternal)
+	}
+	if got, want := len(err.GetValidationErrors()), workers/4; got != want {
+		t.Errorf("%d validation errors, want %d", got, want)
+	}
+	if got, want := len(err.Unwrap()), workers*3/4; got != want {
+		t.Errorf("%d wrapped errors, want %d", got, want)
+	}
+	if !errors.Is(err, err.Unwrap()[0]) {
+		t.Error("errors.Is does not match a wrapped error")
+	}
+}
+
+func TestErrorGroupNoErrors(t *testing.T) {
+	var g erz.ErrorGroup
+	for i := 0; i < 10; i++ {
+		g.Go(func() error { return nil })
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+}
@@ -1,30 +1,99 @@
 package erz
 
 import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"time"
 )
 
 type ValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
-	Value   any    `json:"value,omitempty"`
+	Field   string `json:"field" xml:"field"`
+	Code    string `json:"code,omitempty" xml:"code,omitempty"`
+	Message string `json:"message" xml:"message"`
+	Value   any    `json:"value,omitempty" xml:"value,omitempty"`
+}
+
+type QuotaViolation struct {
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
+}
+
+type PreconditionViolation struct {
+	Type        string `json:"type"`
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
 }
 
 type Error interface {
 	erz()
 	Error() string
+	Format(f fmt.State, verb rune)
 	Code() ErrorCode
+	Timeout() bool
+	Temporary() bool
+	IsServerFault() bool
 	HTTPStatus() int
 	GRPCStatus() *status.Status
+	GRPCStatusWithWrappedTypes() *status.Status
 	GetMessage() string
 	GetDetail() string
+	GetReason() string
+	GetHint() string
+	GetPublicMessage() string
+	PublicError() string
+	PublicErrorLang(lang string) string
+	GetMessageKey() string
 	GetStackTrace() []StackFrame
+	GetFullStack() string
+	GetCacheControl() string
+	GetAllowedMethods() []string
+	GetRequestID() string
+	GetTraceID() string
+	GetTimestamp() time.Time
+	Operation() string
+	GetMetadata() map[string]any
 	GetValidationErrors() []ValidationError
+	GetQuotaViolations() []QuotaViolation
+	GetPreconditionViolations() []PreconditionViolation
+	FieldErrors() map[string][]string
 	WithDetail(detail string) Error
+	WithDetailf(format string, args ...any) Error
+	WithReason(reason string) Error
+	WithHint(hint string) Error
+	WithPublicMessage(message string) Error
+	WithPublicMessagef(format string, args ...any) Error
+	WithMessageKey(key string) Error
+	WithMetadata(key string, value any) Error
+	WithHTTPStatus(status int) Error
+	WithGRPCCode(code codes.Code) Error
+	WithCacheControl(value string) Error
+	WithAllowedMethods(methods ...string) Error
+	WithRequestID(requestID string) Error
+	WithTraceID(traceID string) Error
+	WithContext(ctx context.Context) Error
+	WithTimestamp(t time.Time) Error
+	WithOperation(operation string) Error
 	WithWrapped(err error) Error
+	WithWrappedAll(errs ...error) Error
+	WithCause(err error) Error
 	WithValidationErrors(errs ...ValidationError) Error
+	WithSortedValidationErrors() Error
+	WithValidationError(field, message string, value any) Error
+	WithField(field, message string, value any) Error
+	WithQuotaViolation(subject, description string) Error
+	WithPrecondition(violationType, subject, description string) Error
 	WithStackTrace() Error
-	Unwrap() error
+	WithStackTraceSkip(skip int) Error
+	WithPanicStackTrace() Error
+	WithFullStack() Error
+	Redacted() Error
+	Unwrap() []error
 	ToHTTPResponse(options *HTTPOptions) *HTTPResponse
 	AsJSON(options *HTTPOptions) []byte
+	ToJSON(options *HTTPOptions) ([]byte, error)
+	ToMap() map[string]any
+	MarshalJSON() ([]byte, error)
+	ToWSMessage() ([]byte, error)
 }
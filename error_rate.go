@@ -0,0 +1,89 @@
+package erz
+
+import (
+	"sync"
+	"time"
+)
+
+const errorRateBuckets = 10
+
+// ErrorRate tracks the share of server faults (see Error.IsServerFault) among
+// the errors recorded over a sliding window. The window is divided into ten
+// buckets, so older errors expire in steps of a tenth of the window. It is
+// safe for concurrent use.
+type ErrorRate struct {
+	mu         sync.Mutex
+	bucketSize int64
+	buckets    [errorRateBuckets]errorRateBucket
+}
+
+type errorRateBucket struct {
+	index  int64
+	total  int
+	faults int
+}
+
+func NewErrorRate(window time.Duration) *ErrorRate {
+	return &ErrorRate{
+		bucketSize: max(int64(window/errorRateBuckets), 1),
+	}
+}
+
+// TrackErrorRate creates an ErrorRate and adds it with AddErrorObserver, so
+// every error created by the package is recorded alongside the other
+// observers, such as erzprom's. Errors that wrap other erz errors are counted
+// again. To stop tracking, use NewErrorRate with AddErrorObserver instead.
+func TrackErrorRate(window time.Duration) *ErrorRate {
+	rate := NewErrorRate(window)
+	AddErrorObserver(rate.Record)
+	return rate
+}
+
+// Record counts err in the current bucket. Its signature matches
+// ErrorObserver. Nil errors are ignored.
+func (r *ErrorRate) Record(err Error) {
+	if err == nil {
+		return
+	}
+
+	index := r.currentIndex()
+	fault := err.IsServerFault()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bucket := &r.buckets[index%errorRateBuckets]
+	if bucket.index != index {
+		*bucket = errorRateBucket{index: index}
+	}
+	bucket.total++
+	if fault {
+		bucket.faults++
+	}
+}
+
+// Rate returns server faults divided by all errors recorded within the
+// window, or 0 if there were none.
+func (r *ErrorRate) Rate() float64 {
+	index := r.currentIndex()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var total, faults int
+	for _, bucket := range r.buckets {
+		if bucket.total > 0 && index-bucket.index < errorRateBuckets {
+			total += bucket.total
+			faults += bucket.faults
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return float64(faults) / float64(total)
+}
+
+func (r *ErrorRate) currentIndex() int64 {
+	return now().UnixNano() / r.bucketSize
+}
@@ -0,0 +1,46 @@
+package erzchi
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/intezya/erz"
+	"net/http"
+)
+
+// Middleware recovers panics into erz errors and makes opts available to
+// Render for the rest of the request.
+func Middleware(opts *erz.HTTPOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				r = r.WithContext(erz.ContextWithOptions(r.Context(), opts))
+
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						Render(w, r, erz.RecoverToError(recovered))
+					}
+				}()
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// Render writes err as an erz response envelope. The request ID set by chi's
+// RequestID middleware is used when the options don't provide one.
+func Render(w http.ResponseWriter, r *http.Request, err error) {
+	opts := GetHTTPOptions(r)
+	if opts.RequestID == "" {
+		if requestID := middleware.GetReqID(r.Context()); requestID != "" {
+			withRequestID := *opts
+			withRequestID.RequestID = requestID
+			opts = &withRequestID
+		}
+	}
+
+	erz.DefaultHTTPErrorHandler(w, r, err, opts)
+}
+
+func GetHTTPOptions(r *http.Request) *erz.HTTPOptions {
+	return erz.OptionsFromContext(r.Context())
+}
@@ -0,0 +1,148 @@
+package erzconnect
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"github.com/intezya/erz"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type Options struct {
+	IncludeStackTrace bool
+	Redact            bool
+}
+
+func DefaultOptions() *Options {
+	return &Options{
+		IncludeStackTrace: false,
+	}
+}
+
+// ToConnectError converts err into a *connect.Error with the code and details
+// of its gRPC status, so validation errors, error info and the other erz
+// details reach Connect clients. Stack traces are only attached when
+// opts.IncludeStackTrace is set. Non-erz errors are converted with
+// erz.FromError; nil returns nil.
+func ToConnectError(err error, opts *Options) *connect.Error {
+	if err == nil {
+		return nil
+	}
+
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	erzErr := erz.FromError(err)
+	if opts.Redact {
+		erzErr = erzErr.Redacted()
+	}
+
+	st := erzErr.GRPCStatus()
+	if opts.IncludeStackTrace {
+		st = erzErr.GRPCStatusWithWrappedTypes()
+	}
+	connectErr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+
+	for _, detail := range st.Details() {
+		msg, ok := detail.(proto.Message)
+		if !ok {
+			continue
+		}
+		if _, isDebugInfo := msg.(*errdetails.DebugInfo); isDebugInfo && !opts.IncludeStackTrace {
+			continue
+		}
+		if errorDetail, detailErr := connect.NewErrorDetail(msg); detailErr == nil {
+			connectErr.AddDetail(errorDetail)
+		}
+	}
+
+	return connectErr
+}
+
+// FromConnectError converts a *connect.Error, including its details, back
+// into an erz error via erz.FromGRPCStatusWithDetails. Errors that are not
+// connect errors are converted with erz.FromError.
+func FromConnectError(err error) erz.Error {
+	if err == nil {
+		return nil
+	}
+
+	var erzErr erz.Error
+	if errors.As(err, &erzErr) {
+		return erzErr
+	}
+
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return erz.FromError(err)
+	}
+
+	details := make([]*anypb.Any, 0, len(connectErr.Details()))
+	for _, detail := range connectErr.Details() {
+		details = append(
+			details, &anypb.Any{
+				TypeUrl: "type.googleapis.com/" + detail.Type(),
+				Value:   detail.Bytes(),
+			},
+		)
+	}
+
+	st := status.FromProto(
+		&spb.Status{
+			Code:    int32(connectErr.Code()),
+			Message: connectErr.Message(),
+			Details: details,
+		},
+	)
+	return erz.FromGRPCStatusWithDetails(st)
+}
+
+type interceptor struct {
+	opts *Options
+}
+
+// NewInterceptor returns an interceptor that converts erz errors returned by
+// handlers into connect errors and, on the client side, converts connect
+// errors from unary calls back into erz errors.
+func NewInterceptor(opts *Options) connect.Interceptor {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return &interceptor{opts: opts}
+}
+
+func (i *interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		resp, err := next(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if req.Spec().IsClient {
+			return resp, FromConnectError(err)
+		}
+		return resp, i.toHandlerError(err)
+	}
+}
+
+func (i *interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return i.toHandlerError(next(ctx, conn))
+	}
+}
+
+func (i *interceptor) toHandlerError(err error) error {
+	var erzErr erz.Error
+	if !errors.As(err, &erzErr) {
+		return err
+	}
+	return ToConnectError(erzErr, i.opts)
+}
@@ -2,13 +2,16 @@ package erzfiber
 
 import (
 	"errors"
-	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/intezya/erz"
 	"net/http"
+	"strings"
 )
 
-const httpOptionsContextKey = "erz_http_options"
+const (
+	httpOptionsContextKey = "erz_http_options"
+	warningsContextKey    = "erz_warnings"
+)
 
 func GetHTTPOptions(c *fiber.Ctx) *erz.HTTPOptions {
 	raw := c.Locals(httpOptionsContextKey)
@@ -20,60 +23,108 @@ func GetHTTPOptions(c *fiber.Ctx) *erz.HTTPOptions {
 	return opts
 }
 
+func errorHTTPOptions(c *fiber.Ctx) *erz.HTTPOptions {
+	opts := GetHTTPOptions(c)
+	if opts.Language != "" {
+		return opts
+	}
+
+	lang := erz.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if lang == "" {
+		return opts
+	}
+
+	localized := *opts
+	localized.Language = lang
+	return &localized
+}
+
 func SetHTTPOptions(c *fiber.Ctx, opts *erz.HTTPOptions) {
 	c.Locals(httpOptionsContextKey, opts)
 }
 
+func AddWarning(c *fiber.Ctx, code erz.ErrorCode, message string) {
+	warnings, _ := c.Locals(warningsContextKey).([]erz.Warning)
+	warnings = append(
+		warnings, erz.Warning{
+			Code:    string(code),
+			Message: message,
+		},
+	)
+	c.Locals(warningsContextKey, warnings)
+}
+
+func GetWarnings(c *fiber.Ctx) []erz.Warning {
+	warnings, _ := c.Locals(warningsContextKey).([]erz.Warning)
+	return warnings
+}
+
 func toErzError(err error) erz.Error {
+	if err == nil {
+		return erz.Internal("nil error passed")
+	}
+
 	var erzErr erz.Error
 	if !errors.As(err, &erzErr) {
-		erzErr = erz.InternalWithCause("Unknown error", err)
+		return erz.InternalWithCause("Unknown error", err)
+	}
+	if e, ok := erzErr.(*erz.Er); ok && e == nil {
+		return erz.Internal("nil error passed")
 	}
 	return erzErr
 }
 
 func HandleError(c *fiber.Ctx, err error) error {
-	erzErr := toErzError(err)
-	opts := GetHTTPOptions(c)
+	return writeError(c, toErzError(err))
+}
+
+func writeError(c *fiber.Ctx, erzErr erz.Error) error {
+	opts := errorHTTPOptions(c)
+	if opts.ErrorLogger != nil {
+		opts.ErrorLogger(c.UserContext(), erzErr)
+	}
 	resp := erzErr.ToHTTPResponse(opts)
 
-	return c.Status(erzErr.HTTPStatus()).JSON(resp)
+	if cacheControl := erzErr.GetCacheControl(); cacheControl != "" {
+		c.Set(fiber.HeaderCacheControl, cacheControl)
+	}
+	if methods := erzErr.GetAllowedMethods(); len(methods) > 0 {
+		c.Set(fiber.HeaderAllow, strings.Join(methods, ", "))
+	}
+
+	return send(c, erzErr.HTTPStatus(), resp, opts)
 }
 
 func WriteFiberSuccessResponse(c *fiber.Ctx, data interface{}) error {
 	opts := GetHTTPOptions(c)
 	response := erz.CreateSuccessResponse(data, opts)
+	response.Warnings = GetWarnings(c)
 
-	return c.Status(http.StatusOK).JSON(response)
+	return send(c, http.StatusOK, response, opts)
+}
+
+// send encodes v with the Serializer of opts, JSON unless one is set.
+func send(c *fiber.Ctx, status int, v interface{}, opts *erz.HTTPOptions) error {
+	body, contentType, err := opts.Encode(v)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Status(status).Send(body)
 }
 
 type ErrorHandler func(error, *fiber.Ctx) error
 
 func DefaultErrorHandler(err error, c *fiber.Ctx) error {
-	erzErr := toErzError(err)
-	opts := GetHTTPOptions(c)
-	resp := erzErr.ToHTTPResponse(opts)
-
-	return c.Status(erzErr.HTTPStatus()).JSON(resp)
+	return writeError(c, toErzError(err))
 }
 
 func RecoverMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		defer func() {
 			if recovered := recover(); recovered != nil {
-				var err error
-
-				switch v := recovered.(type) {
-				case error:
-					err = v
-				case string:
-					err = errors.New(v)
-				default:
-					err = fmt.Errorf("panic recovered: %v", v)
-				}
-
-				erzErr := erz.InternalWithCause("panic recovered", err)
-				DefaultErrorHandler(erzErr, c)
+				DefaultErrorHandler(erz.RecoverToError(recovered), c)
 			}
 		}()
 
@@ -0,0 +1,136 @@
+package erzfiber_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/gofiber/fiber/v2"
+	"github.com/intezya/erz"
+	"github.com/intezya/erz/erzfiber"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestErrorMiddlewareTypedNil(t *testing.T) {
+	var logged erz.ErrorCode
+	opts := erz.DefaultHTTPOptions()
+	opts.ErrorLogger = func(_ context.Context, err erz.Error) {
+		logged = err.Code()
+	}
+
+	app := fiber.New()
+	app.Use(
+		func(c *fiber.Ctx) error {
+			erzfiber.SetHTTPOptions(c, opts)
+			return c.Next()
+		},
+	)
+	app.Use(erzfiber.ErrorMiddleware())
+	app.Get(
+		"/", func(c *fiber.Ctx) error {
+			var err *erz.Er
+			return err
+		},
+	)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	var envelope erz.HTTPResponse
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("invalid body %q: %v", body, err)
+	}
+	if envelope.Error == nil || envelope.Error.Code != string(erz.CodeInternal) {
+		t.Errorf("body %s, want an INTERNAL error", body)
+	}
+	if logged != erz.CodeInternal {
+		t.Errorf("ErrorLogger got code %q, want %q", logged, erz.CodeInternal)
+	}
+}
+
+func TestWriteFiberSuccessResponseWarnings(t *testing.T) {
+	app := fiber.New()
+	app.Get(
+		"/", func(c *fiber.Ctx) error {
+			erzfiber.AddWarning(c, "UNKNOWN_FIELD", "field 'colour' was ignored")
+			return erzfiber.WriteFiberSuccessResponse(c, map[string]string{"id": "1"})
+		},
+	)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var envelope erz.HTTPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatal(err)
+	}
+	want := []erz.Warning{{Code: "UNKNOWN_FIELD", Message: "field 'colour' was ignored"}}
+	if resp.StatusCode != http.StatusOK || !slices.Equal(envelope.Warnings, want) {
+		t.Errorf("got status %d, warnings %+v; want 200, %+v", resp.StatusCode, envelope.Warnings, want)
+	}
+}
+
+func TestFiberResponsesUseSerializer(t *testing.T) {
+	opts := erz.DefaultHTTPOptions()
+	opts.Serializer = erz.XMLSerializer{}
+
+	app := fiber.New()
+	app.Use(
+		func(c *fiber.Ctx) error {
+			erzfiber.SetHTTPOptions(c, opts)
+			return c.Next()
+		},
+	)
+	app.Use(erzfiber.ErrorMiddleware())
+	app.Get(
+		"/error", func(c *fiber.Ctx) error {
+			return erz.NotFound("user")
+		},
+	)
+	app.Get(
+		"/ok", func(c *fiber.Ctx) error {
+			return erzfiber.WriteFiberSuccessResponse(c, "done")
+		},
+	)
+
+	for _, tt := range []struct {
+		path   string
+		status int
+	}{
+		{"/error", http.StatusNotFound},
+		{"/ok", http.StatusOK},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := resp.Header.Get(fiber.HeaderContentType); got != erz.MediaTypeXML {
+				t.Errorf("Content-Type %q, want %q", got, erz.MediaTypeXML)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			var envelope erz.HTTPResponse
+			if err := xml.Unmarshal(body, &envelope); err != nil {
+				t.Fatalf("invalid XML body %q: %v", body, err)
+			}
+		})
+	}
+}
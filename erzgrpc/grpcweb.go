@@ -0,0 +1,86 @@
+package erzgrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	TrailerStatus        = "grpc-status"
+	TrailerMessage       = "grpc-message"
+	TrailerStatusDetails = "grpc-status-details-bin"
+)
+
+// trailerFrameFlag marks a gRPC-Web frame as carrying trailers.
+const trailerFrameFlag = 0x80
+
+// WebTrailers returns the gRPC-Web trailers for err: grpc-status with the
+// numeric code, grpc-message with the percent-encoded message, and
+// grpc-status-details-bin with the base64 encoded google.rpc.Status holding
+// the erz details (validation errors, error info, ...). Errors are converted
+// as by UnaryServerInterceptor with opts; a nil err yields an OK status.
+func WebTrailers(err error, opts *Options) http.Header {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	st := status.Convert(toStatusError(err, opts))
+
+	trailers := http.Header{}
+	trailers[TrailerStatus] = []string{strconv.Itoa(int(st.Code()))}
+	if msg := st.Message(); msg != "" {
+		trailers[TrailerMessage] = []string{encodeGRPCMessage(msg)}
+	}
+	if pb := st.Proto(); pb != nil && len(pb.Details) > 0 {
+		if bin, marshalErr := proto.Marshal(pb); marshalErr == nil {
+			trailers[TrailerStatusDetails] = []string{base64.RawStdEncoding.EncodeToString(bin)}
+		}
+	}
+
+	return trailers
+}
+
+// WebTrailerFrame encodes trailers as the final frame of a gRPC-Web response
+// body, as expected by browser clients that cannot read HTTP trailers.
+func WebTrailerFrame(trailers http.Header) []byte {
+	keys := make([]string, 0, len(trailers))
+	for key := range trailers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var payload bytes.Buffer
+	for _, key := range keys {
+		for _, value := range trailers[key] {
+			fmt.Fprintf(&payload, "%s:%s\r\n", strings.ToLower(key), value)
+		}
+	}
+
+	frame := make([]byte, 5, 5+payload.Len())
+	frame[0] = trailerFrameFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(payload.Len()))
+	return append(frame, payload.Bytes()...)
+}
+
+// encodeGRPCMessage percent-encodes msg as required for the grpc-message
+// header: printable ASCII except '%' is kept as is.
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
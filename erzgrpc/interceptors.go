@@ -0,0 +1,152 @@
+package erzgrpc
+
+import (
+	"context"
+	"errors"
+	"github.com/intezya/erz"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type Options struct {
+	IncludeStackTrace    bool
+	Redact               bool
+	SortValidationErrors bool
+}
+
+func DefaultOptions() *Options {
+	return &Options{
+		IncludeStackTrace: false,
+	}
+}
+
+func UnaryServerInterceptor(opts *Options) grpc.UnaryServerInterceptor {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, toStatusError(err, opts)
+	}
+}
+
+func StreamServerInterceptor(opts *Options) grpc.StreamServerInterceptor {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return toStatusError(handler(srv, ss), opts)
+	}
+}
+
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		callOpts ...grpc.CallOption,
+	) error {
+		return fromStatusError(invoker(ctx, method, req, reply, cc, callOpts...))
+	}
+}
+
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		callOpts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			return nil, fromStatusError(err)
+		}
+		return &clientStream{ClientStream: cs}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return fromStatusError(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return fromStatusError(s.ClientStream.RecvMsg(m))
+}
+
+func toStatusError(err error, opts *Options) error {
+	var erzErr erz.Error
+	if !errors.As(err, &erzErr) {
+		return err
+	}
+
+	if opts.Redact {
+		erzErr = erzErr.Redacted()
+	}
+	if opts.SortValidationErrors {
+		erzErr = erzErr.WithSortedValidationErrors()
+	}
+
+	var st *status.Status
+	if opts.IncludeStackTrace {
+		st = erzErr.GRPCStatusWithWrappedTypes()
+	} else {
+		st = withoutDebugInfo(erzErr.GRPCStatus())
+	}
+
+	return st.Err()
+}
+
+func fromStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var erzErr erz.Error
+	if errors.As(err, &erzErr) {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	return erz.FromGRPCStatusWithDetails(st)
+}
+
+func withoutDebugInfo(st *status.Status) *status.Status {
+	pb := st.Proto()
+
+	details := make([]*anypb.Any, 0, len(pb.Details))
+	for _, detail := range pb.Details {
+		if detail.MessageIs(&errdetails.DebugInfo{}) {
+			continue
+		}
+		details = append(details, detail)
+	}
+	pb.Details = details
+
+	return status.FromProto(pb)
+}
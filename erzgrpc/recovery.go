@@ -0,0 +1,49 @@
+package erzgrpc
+
+import (
+	"context"
+	"github.com/intezya/erz"
+	"google.golang.org/grpc"
+)
+
+func UnaryServerRecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = panicToError(recovered)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerRecoveryInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = panicToError(recovered)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// panicToError has to be called directly from the deferred recover so that
+// the goroutine stack still contains the frames of the panic site. It returns
+// the erz error rather than a status error, so UnaryServerInterceptor and
+// StreamServerInterceptor apply their Options to it.
+func panicToError(recovered interface{}) error {
+	return erz.RecoverToError(recovered)
+}
@@ -0,0 +1,38 @@
+package erzmsgpack
+
+import (
+	"bytes"
+	"github.com/intezya/erz"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+)
+
+const MediaType = "application/msgpack"
+
+// Serializer encodes response envelopes as MessagePack. Field names follow the
+// json struct tags so both formats share the same keys.
+type Serializer struct{}
+
+func (Serializer) ContentType() string {
+	return MediaType
+}
+
+func (s Serializer) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (Serializer) NewEncoder(w io.Writer) erz.Encoder {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc
+}
+
+// Register makes the serializer available for content negotiation under
+// application/msgpack.
+func Register() {
+	erz.RegisterSerializer(MediaType, Serializer{})
+}
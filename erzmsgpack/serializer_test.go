@@ -0,0 +1,42 @@
+package erzmsgpack_test
+
+import (
+	"bytes"
+	"github.com/intezya/erz"
+	"github.com/intezya/erz/erzmsgpack"
+	"github.com/vmihailenco/msgpack/v5"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSerializerRoundTrip(t *testing.T) {
+	opts := erz.DefaultHTTPOptions()
+	opts.IncludeTimestamp = true
+	want := erz.Validation("invalid order").
+		WithReason("ORDER_INVALID").
+		WithRequestID("req-1").
+		WithValidationError("items[0].price", "must be positive", "-1").
+		WithTimestamp(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)).
+		ToHTTPResponse(opts)
+
+	data, err := erzmsgpack.Serializer{}.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got erz.HTTPResponse
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	if err := dec.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Timestamp.Equal(want.Timestamp) {
+		t.Errorf("timestamp %v, want %v", got.Timestamp, want.Timestamp)
+	}
+	got.Timestamp = want.Timestamp
+	if !reflect.DeepEqual(&got, want) {
+		t.Errorf("round trip changed the response:\n got %+v\nwant %+v", got.Error, want.Error)
+	}
+}
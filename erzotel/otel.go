@@ -0,0 +1,95 @@
+package erzotel
+
+import (
+	"context"
+	"errors"
+	"github.com/intezya/erz"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"net/http"
+)
+
+const (
+	AttributeCode             = attribute.Key("erz.code")
+	AttributeValidationErrors = attribute.Key("erz.validation_errors")
+)
+
+// ExtractContextIDs returns the trace and span IDs of the span context stored
+// in ctx. Install it with erz.SetContextExtractor to make erz's WithContext
+// attach them to errors.
+func ExtractContextIDs(ctx context.Context) erz.ContextIDs {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return erz.ContextIDs{}
+	}
+
+	return erz.ContextIDs{
+		TraceID: spanContext.TraceID().String(),
+		SpanID:  spanContext.SpanID().String(),
+	}
+}
+
+// RecordError records err on the span stored in ctx and marks the span as
+// failed. It does nothing if err is nil or ctx carries no recording span.
+func RecordError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	var erzErr erz.Error
+	if !errors.As(err, &erzErr) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		AttributeCode.String(string(erzErr.Code())),
+		AttributeValidationErrors.Int(len(erzErr.GetValidationErrors())),
+	}
+
+	span.RecordError(err, trace.WithAttributes(attrs...))
+	span.SetStatus(codes.Error, string(erzErr.Code()))
+	span.SetAttributes(attrs...)
+}
+
+func HandlerFunc(h erz.HandlerFunc) erz.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := h(w, r)
+		RecordError(r.Context(), err)
+		return err
+	}
+}
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		RecordError(ctx, err)
+		return resp, err
+	}
+}
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		err := handler(srv, ss)
+		RecordError(ss.Context(), err)
+		return err
+	}
+}
@@ -0,0 +1,69 @@
+package erzprom
+
+import (
+	"errors"
+	"github.com/intezya/erz"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func NewErrorCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "erz_errors_total",
+			Help: "Number of erz errors created, by error code and operation.",
+		},
+		[]string{"code", "operation"},
+	)
+}
+
+// Observer counts errors on a counter created by NewErrorCounter. The
+// operation label is the one the error is created with, e.g. through
+// erz.ErBuilder, and empty for errors that get it later from WithOperation.
+func Observer(counter *prometheus.CounterVec) erz.ErrorObserver {
+	return func(err erz.Error) {
+		counter.WithLabelValues(string(err.Code()), err.Operation()).Inc()
+	}
+}
+
+// NewOperationErrorCounter returns a counter labeled by code and operation.
+// The observer installed by Register runs when an error is created, before
+// WithOperation can be applied, so feed this counter with ObserveOperation
+// where errors leave the operation instead.
+func NewOperationErrorCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "erz_operation_errors_total",
+			Help: "Number of erz errors returned, by error code and operation.",
+		},
+		[]string{"code", "operation"},
+	)
+}
+
+// ObserveOperation counts err on a counter created by
+// NewOperationErrorCounter. Non-erz errors are counted with the code
+// erz.CodeOf gives them and no operation; nil errors are ignored. No error is
+// created, so the erz error observer is not called again.
+func ObserveOperation(counter *prometheus.CounterVec, err error) {
+	if err == nil {
+		return
+	}
+
+	var operation string
+	var erzErr erz.Error
+	if errors.As(err, &erzErr) {
+		operation = erzErr.Operation()
+	}
+	counter.WithLabelValues(string(erz.CodeOf(err)), operation).Inc()
+}
+
+// Register registers an error counter with registerer and installs it as the
+// erz error observer.
+func Register(registerer prometheus.Registerer) (*prometheus.CounterVec, error) {
+	counter := NewErrorCounter()
+	if err := registerer.Register(counter); err != nil {
+		return nil, err
+	}
+
+	erz.SetErrorObserver(Observer(counter))
+	return counter, nil
+}
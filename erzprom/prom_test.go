@@ -0,0 +1,41 @@
+package erzprom_test
+
+import (
+	"github.com/intezya/erz"
+	"github.com/intezya/erz/erzprom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"testing"
+)
+
+func TestRegisterCountsOperation(t *testing.T) {
+	counter, err := erzprom.Register(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer erz.SetErrorObserver(nil)
+
+	_ = erz.Build(erz.ErBuilder{Code: erz.CodeInternal, Message: "failed to create order", Operation: "CreateOrder"})
+	_ = erz.NotFound("order").WithOperation("GetOrder")
+
+	if got := testutil.ToFloat64(counter.WithLabelValues(string(erz.CodeInternal), "CreateOrder")); got != 1 {
+		t.Errorf("CreateOrder count %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(counter.WithLabelValues(string(erz.CodeNotFound), "")); got != 1 {
+		t.Errorf("count without operation %v, want 1", got)
+	}
+}
+
+func TestObserveOperation(t *testing.T) {
+	counter := erzprom.NewOperationErrorCounter()
+
+	erzprom.ObserveOperation(counter, erz.NotFound("order").WithOperation("GetOrder"))
+	erzprom.ObserveOperation(counter, nil)
+
+	if got := testutil.ToFloat64(counter.WithLabelValues(string(erz.CodeNotFound), "GetOrder")); got != 1 {
+		t.Errorf("GetOrder count %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(counter); got != 1 {
+		t.Errorf("%d series, want 1", got)
+	}
+}
@@ -0,0 +1,113 @@
+package erzsentry
+
+import (
+	"errors"
+	"github.com/getsentry/sentry-go"
+	"github.com/intezya/erz"
+	"net/http"
+)
+
+// CaptureError reports err to Sentry using the current hub.
+func CaptureError(err error) *sentry.EventID {
+	return CaptureErrorWithHub(sentry.CurrentHub(), err)
+}
+
+// CaptureErrorWithHub reports err to Sentry using hub. erz errors are sent
+// with a level derived from their code, their stack trace, and the validation
+// errors and metadata as event context. Other errors are captured as plain
+// exceptions.
+func CaptureErrorWithHub(hub *sentry.Hub, err error) *sentry.EventID {
+	if err == nil || hub == nil {
+		return nil
+	}
+
+	var erzErr erz.Error
+	if !errors.As(err, &erzErr) {
+		return hub.CaptureException(err)
+	}
+
+	return hub.CaptureEvent(newEvent(erzErr))
+}
+
+func newEvent(erzErr erz.Error) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Level = Level(erzErr)
+	event.Tags["erz.code"] = string(erzErr.Code())
+	event.Exception = []sentry.Exception{
+		{
+			Type:       string(erzErr.Code()),
+			Value:      erzErr.Error(),
+			Stacktrace: stacktrace(erzErr.GetStackTrace()),
+		},
+	}
+
+	context := sentry.Context{
+		"code":    string(erzErr.Code()),
+		"message": erzErr.GetMessage(),
+	}
+	if detail := erzErr.GetDetail(); detail != "" {
+		context["detail"] = detail
+	}
+	if validationErrors := erzErr.GetValidationErrors(); len(validationErrors) > 0 {
+		context["validation_errors"] = validationErrors
+	}
+	event.Contexts["erz"] = context
+
+	if metadata := erzErr.GetMetadata(); len(metadata) > 0 {
+		event.Contexts["erz.metadata"] = metadata
+	}
+
+	return event
+}
+
+// Level maps an error to a Sentry level: errors answered with a 5xx status
+// are reported as errors, everything else as warnings.
+func Level(erzErr erz.Error) sentry.Level {
+	if erzErr.HTTPStatus() >= http.StatusInternalServerError {
+		return sentry.LevelError
+	}
+	return sentry.LevelWarning
+}
+
+func stacktrace(stackTrace []erz.StackFrame) *sentry.Stacktrace {
+	if len(stackTrace) == 0 {
+		return nil
+	}
+
+	// Sentry expects the outermost frame first, erz stores the innermost first.
+	frames := make([]sentry.Frame, 0, len(stackTrace))
+	for i := len(stackTrace) - 1; i >= 0; i-- {
+		frame := stackTrace[i]
+		frames = append(
+			frames, sentry.Frame{
+				Function: frame.Function,
+				Module:   frame.Package,
+				Filename: frame.File,
+				AbsPath:  frame.FullPath,
+				Lineno:   frame.Line,
+				InApp:    true,
+			},
+		)
+	}
+
+	return &sentry.Stacktrace{Frames: frames}
+}
+
+// HandlerFunc reports errors returned by h to Sentry when they result in a
+// 5xx response. The hub is taken from the request context if one is set.
+func HandlerFunc(h erz.HandlerFunc) erz.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := h(w, r)
+		if err == nil || erz.HTTPStatusOf(err) < http.StatusInternalServerError {
+			return err
+		}
+
+		hub := sentry.GetHubFromContext(r.Context())
+		if hub == nil {
+			hub = sentry.CurrentHub()
+		}
+		CaptureErrorWithHub(hub, err)
+
+		return err
+	}
+}
@@ -0,0 +1,67 @@
+package erzsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/intezya/erz"
+)
+
+const (
+	SQLStateNotNullViolation     = "23502"
+	SQLStateForeignKeyViolation  = "23503"
+	SQLStateUniqueViolation      = "23505"
+	SQLStateCheckViolation       = "23514"
+	SQLStateSerializationFailure = "40001"
+	SQLStateDeadlockDetected     = "40P01"
+)
+
+// sqlStateError is implemented by driver errors that expose their SQLSTATE,
+// such as *pgconn.PgError (pgx) and *pq.Error (lib/pq).
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+// FromSQLError maps database errors to erz codes while keeping the original
+// error wrapped for errors.Is and errors.As:
+//
+//   - sql.ErrNoRows becomes CodeNotFound
+//   - unique violations become CodeAlreadyExists
+//   - foreign key, not-null and check violations become CodeInvalidInput
+//   - serialization failures and deadlocks become CodeConflict
+//   - context cancellation and deadlines are converted with erz.FromError
+//
+// Anything else becomes CodeInternal. Existing erz errors are returned as-is.
+func FromSQLError(err error) erz.Error {
+	if err == nil {
+		return nil
+	}
+
+	var erzErr erz.Error
+	if errors.As(err, &erzErr) {
+		return erzErr
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return erz.Wrap(err, erz.CodeNotFound, "record not found")
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return erz.FromError(err)
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		switch stateErr.SQLState() {
+		case SQLStateUniqueViolation:
+			return erz.Wrap(err, erz.CodeAlreadyExists, "record already exists")
+		case SQLStateForeignKeyViolation, SQLStateNotNullViolation, SQLStateCheckViolation:
+			return erz.Wrap(err, erz.CodeInvalidInput, "constraint violation")
+		case SQLStateSerializationFailure, SQLStateDeadlockDetected:
+			return erz.Wrap(err, erz.CodeConflict, "transaction conflict")
+		}
+	}
+
+	return erz.Wrap(err, erz.CodeInternal, "database error")
+}
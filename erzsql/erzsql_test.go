@@ -0,0 +1,67 @@
+package erzsql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/intezya/erz"
+	"github.com/intezya/erz/erzsql"
+	"testing"
+)
+
+// stateError stands in for driver errors such as *pgconn.PgError.
+type stateError struct {
+	state string
+}
+
+func (e *stateError) Error() string    { return "sqlstate " + e.state }
+func (e *stateError) SQLState() string { return e.state }
+
+var errUniqueViolation = &stateError{state: erzsql.SQLStateUniqueViolation}
+
+func TestFromSQLError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		want     erz.ErrorCode
+	}{
+		{"no rows", sql.ErrNoRows, sql.ErrNoRows, erz.CodeNotFound},
+		{"wrapped no rows", fmt.Errorf("get user: %w", sql.ErrNoRows), sql.ErrNoRows, erz.CodeNotFound},
+		{"unique violation", errUniqueViolation, errUniqueViolation, erz.CodeAlreadyExists},
+		{"wrapped unique violation", fmt.Errorf("insert user: %w", errUniqueViolation), errUniqueViolation, erz.CodeAlreadyExists},
+		{"foreign key violation", &stateError{state: erzsql.SQLStateForeignKeyViolation}, nil, erz.CodeInvalidInput},
+		{"not-null violation", &stateError{state: erzsql.SQLStateNotNullViolation}, nil, erz.CodeInvalidInput},
+		{"check violation", &stateError{state: erzsql.SQLStateCheckViolation}, nil, erz.CodeInvalidInput},
+		{"deadlock", &stateError{state: erzsql.SQLStateDeadlockDetected}, nil, erz.CodeConflict},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), context.DeadlineExceeded, erz.CodeTimeout},
+		{"conn done", sql.ErrConnDone, sql.ErrConnDone, erz.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := erzsql.FromSQLError(tt.err)
+			if err.Code() != tt.want {
+				t.Errorf("code %s, want %s", err.Code(), tt.want)
+			}
+			if tt.sentinel != nil && !errors.Is(err, tt.sentinel) {
+				t.Errorf("errors.Is(err, %v) = false", tt.sentinel)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("the original error is not wrapped")
+			}
+		})
+	}
+}
+
+func TestFromSQLErrorPassthrough(t *testing.T) {
+	if err := erzsql.FromSQLError(nil); err != nil {
+		t.Errorf("FromSQLError(nil) = %v", err)
+	}
+
+	original := erz.NotFound("user")
+	if err := erzsql.FromSQLError(fmt.Errorf("repo: %w", original)); err != original {
+		t.Errorf("got %v, want the wrapped erz error", err)
+	}
+}
@@ -0,0 +1,103 @@
+package erztest
+
+import (
+	"fmt"
+	"github.com/intezya/erz"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// DiffValidationErrors describes how actual differs from expected, one line
+// per field: "- " for missing violations, "+ " for extra ones and "~ " for
+// violations whose code or message differ. Fields are compared in sorted
+// order and values are ignored. It returns "" if both match.
+func DiffValidationErrors(expected, actual []erz.ValidationError) string {
+	expectedByField := groupByField(expected)
+	actualByField := groupByField(actual)
+
+	fields := make([]string, 0, len(expectedByField)+len(actualByField))
+	for field := range expectedByField {
+		fields = append(fields, field)
+	}
+	for field := range actualByField {
+		if _, ok := expectedByField[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var b strings.Builder
+	for _, field := range fields {
+		missing, extra := unmatched(expectedByField[field], actualByField[field])
+
+		for len(missing) > 0 && len(extra) > 0 {
+			fmt.Fprintf(&b, "~ %s: expected %s, got %s\n", field, describe(missing[0]), describe(extra[0]))
+			missing, extra = missing[1:], extra[1:]
+		}
+		for _, ve := range missing {
+			fmt.Fprintf(&b, "- %s: %s\n", field, describe(ve))
+		}
+		for _, ve := range extra {
+			fmt.Fprintf(&b, "+ %s: %s\n", field, describe(ve))
+		}
+	}
+
+	return b.String()
+}
+
+// AssertValidationErrors fails the test with a DiffValidationErrors report
+// unless err carries exactly the expected validation errors.
+func AssertValidationErrors(t testing.TB, err erz.Error, expected []erz.ValidationError) {
+	t.Helper()
+
+	var actual []erz.ValidationError
+	if err != nil {
+		actual = err.GetValidationErrors()
+	}
+	if diff := DiffValidationErrors(expected, actual); diff != "" {
+		t.Errorf("validation errors mismatch (-missing +extra ~changed):\n%s", diff)
+	}
+}
+
+func groupByField(errs []erz.ValidationError) map[string][]erz.ValidationError {
+	grouped := make(map[string][]erz.ValidationError, len(errs))
+	for _, ve := range errs {
+		grouped[ve.Field] = append(grouped[ve.Field], ve)
+	}
+	return grouped
+}
+
+// unmatched drops the violations that appear in both lists, keeping the
+// remaining ones in their original order.
+func unmatched(expected, actual []erz.ValidationError) (missing, extra []erz.ValidationError) {
+	used := make([]bool, len(actual))
+
+	for _, want := range expected {
+		found := false
+		for i, got := range actual {
+			if !used[i] && got.Code == want.Code && got.Message == want.Message {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, want)
+		}
+	}
+
+	for i, got := range actual {
+		if !used[i] {
+			extra = append(extra, got)
+		}
+	}
+	return missing, extra
+}
+
+func describe(ve erz.ValidationError) string {
+	if ve.Code == "" {
+		return fmt.Sprintf("%q", ve.Message)
+	}
+	return fmt.Sprintf("%q (%s)", ve.Message, ve.Code)
+}
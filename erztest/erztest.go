@@ -0,0 +1,29 @@
+package erztest
+
+import (
+	"github.com/intezya/erz"
+	"testing"
+)
+
+// AssertCode fails the test unless err is an erz error with the given code.
+func AssertCode(t testing.TB, err error, code erz.ErrorCode) {
+	t.Helper()
+
+	actual, ok := erz.Code(err)
+	if !ok {
+		t.Errorf("expected erz error with code %s, got %v", code, err)
+		return
+	}
+	if actual != code {
+		t.Errorf("expected error code %s, got %s (%v)", code, actual, err)
+	}
+}
+
+// AssertEqual fails the test unless erz.Equal reports the errors as equal.
+func AssertEqual(t testing.TB, expected, actual erz.Error) {
+	t.Helper()
+
+	if !erz.Equal(expected, actual) {
+		t.Errorf("errors are not equal:\nexpected: %+v\nactual:   %+v", expected, actual)
+	}
+}
@@ -0,0 +1,80 @@
+package erzvalidator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/intezya/erz"
+	"strings"
+)
+
+// FromValidationErrors converts validator.ValidationErrors into a CodeValidation
+// error with one ValidationError per failed field. The validation tag is used
+// as the code. Other errors are converted with erz.FromError.
+func FromValidationErrors(err error) erz.Error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return erz.FromError(err)
+	}
+
+	fieldErrors := make([]erz.ValidationError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fieldErrors = append(
+			fieldErrors, erz.ValidationError{
+				Field:   fieldPath(fe),
+				Code:    fe.Tag(),
+				Message: message(fe),
+				Value:   fe.Value(),
+			},
+		)
+	}
+
+	return erz.ValidationWithErrors("validation failed", fieldErrors)
+}
+
+// fieldPath drops the top-level struct name from the namespace, so a nested
+// field reads "address.city" rather than "User.address.city".
+func fieldPath(fe validator.FieldError) string {
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "len":
+		return fmt.Sprintf("must have length %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	}
+
+	if fe.Param() != "" {
+		return fmt.Sprintf("failed on the '%s=%s' validation", fe.Tag(), fe.Param())
+	}
+	return fmt.Sprintf("failed on the '%s' validation", fe.Tag())
+}
@@ -0,0 +1,52 @@
+package erzzap
+
+import (
+	"errors"
+	"fmt"
+	"github.com/intezya/erz"
+	"go.uber.org/zap"
+)
+
+func ZapFields(err error) []zap.Field {
+	return zapFields(err, false)
+}
+
+func ZapFieldsWithStack(err error) []zap.Field {
+	return zapFields(err, true)
+}
+
+func zapFields(err error, includeStackTrace bool) []zap.Field {
+	if err == nil {
+		return nil
+	}
+
+	var erzErr erz.Error
+	if !errors.As(err, &erzErr) {
+		return []zap.Field{zap.Error(err)}
+	}
+
+	fields := []zap.Field{
+		zap.String("code", string(erzErr.Code())),
+		zap.String("message", erzErr.GetMessage()),
+	}
+
+	if detail := erzErr.GetDetail(); detail != "" {
+		fields = append(fields, zap.String("detail", detail))
+	}
+
+	if validationErrors := erzErr.GetValidationErrors(); len(validationErrors) > 0 {
+		fields = append(fields, zap.Int("validation_errors", len(validationErrors)))
+	}
+
+	if includeStackTrace {
+		if stackTrace := erzErr.GetStackTrace(); len(stackTrace) > 0 {
+			frames := make([]string, 0, len(stackTrace))
+			for _, frame := range stackTrace {
+				frames = append(frames, fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function))
+			}
+			fields = append(fields, zap.Strings("stack_trace", frames))
+		}
+	}
+
+	return fields
+}
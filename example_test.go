@@ -0,0 +1,32 @@
+package erz_test
+
+import (
+	"fmt"
+	"github.com/intezya/erz"
+)
+
+func ExampleFieldError() {
+	err := erz.FieldError("email", "is required", nil).
+		WithField("age", "must be positive", -1).
+		WithField("name", "is too short", "al")
+
+	fmt.Println(err.Code())
+	for _, ve := range err.GetValidationErrors() {
+		fmt.Printf("%s: %s (%v)\n", ve.Field, ve.Message, ve.Value)
+	}
+	// Output:
+	// VALIDATION
+	// email: is required (<nil>)
+	// age: must be positive (-1)
+	// name: is too short (al)
+}
+
+func ExampleEr_WithField() {
+	err := erz.InvalidInput("signup form").
+		WithField("password", "is too weak", nil).
+		WithField("password", "must differ from the username", nil)
+
+	fmt.Println(err.Code(), len(err.GetValidationErrors()))
+	// Output:
+	// VALIDATION 2
+}
@@ -0,0 +1,3 @@
+package erz
+
+var NewStackFrame = newStackFrame
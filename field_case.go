@@ -0,0 +1,69 @@
+package erz
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SnakeCaseField converts every name in a field path to snake_case, keeping
+// array indices: "Items[3].UnitPrice" becomes "items[3].unit_price". Use it
+// with SetValidationFieldTransformer.
+func SnakeCaseField(field string) string {
+	return mapFieldNames(field, snakeCase)
+}
+
+// CamelCaseField converts every name in a field path to camelCase:
+// "Items[3].UnitPrice" and "items[3].unit_price" both become
+// "items[3].unitPrice". Use it with SetValidationFieldTransformer.
+func CamelCaseField(field string) string {
+	return mapFieldNames(field, camelCase)
+}
+
+func mapFieldNames(path string, convert func(string) string) string {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		name, index := segment, ""
+		if bracket := strings.IndexByte(segment, '['); bracket >= 0 {
+			name, index = segment[:bracket], segment[bracket:]
+		}
+		segments[i] = convert(name) + index
+	}
+	return strings.Join(segments, ".")
+}
+
+// snakeCase splits words before an upper case letter that follows a lower case
+// letter or digit, or that starts a word after an acronym ("HTTPStatus"
+// becomes "http_status").
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prev != '_' && (unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower)) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func camelCase(name string) string {
+	words := strings.Split(snakeCase(name), "_")
+	var b strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString(word)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
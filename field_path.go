@@ -0,0 +1,35 @@
+package erz
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldPath builds a validation field path from its segments. Strings are
+// joined with dots and ints become array indices, so FieldPath("items", 3,
+// "price") returns "items[3].price". The path is sent unchanged in the HTTP
+// envelope and as the gRPC BadRequest field.
+func FieldPath(segments ...any) string {
+	var path string
+	for _, segment := range segments {
+		if index, ok := segment.(int); ok {
+			path += "[" + strconv.Itoa(index) + "]"
+			continue
+		}
+		path = joinFieldPath(path, fmt.Sprint(segment))
+	}
+	return path
+}
+
+func joinFieldPath(prefix, field string) string {
+	switch {
+	case prefix == "":
+		return field
+	case field == "":
+		return prefix
+	case strings.HasPrefix(field, "["):
+		return prefix + field
+	}
+	return prefix + "." + field
+}
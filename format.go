@@ -0,0 +1,52 @@
+package erz
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format implements fmt.Formatter. %s and %v print Error(), %q prints it
+// quoted, and %+v prints the code, message, detail, wrapped errors with their
+// Go types and stack trace.
+func (e *Er) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('+') {
+			_, _ = io.WriteString(f, e.verbose())
+			return
+		}
+		_, _ = io.WriteString(f, e.Error())
+	case 's':
+		_, _ = io.WriteString(f, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(f, "%q", e.Error())
+	}
+}
+
+func (e *Er) verbose() string {
+	if e == nil {
+		return errNilPassed().verbose()
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s: %s", e.errCode, e.message)
+
+	if e.detail != "" {
+		fmt.Fprintf(&b, "\ndetail: %s", e.detail)
+	}
+
+	for _, wrappedErr := range e.wrapped {
+		fmt.Fprintf(&b, "\ncaused by: (%T) %v", wrappedErr, wrappedErr)
+	}
+
+	if stackTrace := e.GetStackTrace(); len(stackTrace) > 0 {
+		b.WriteString("\nstack trace:")
+		for _, frame := range stackTrace {
+			fmt.Fprintf(&b, "\n\t%s:%d %s", frame.File, frame.Line, frame.Function)
+		}
+	}
+
+	return b.String()
+}
@@ -7,34 +7,60 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/protoadapt"
+	"strconv"
 	"strings"
+	"sync"
 )
 
+const (
+	debugInfoDetail    = "Go stack trace"
+	wrappedEntryPrefix = "wrapped "
+)
+
+// statusCache memoizes GRPCStatus. Errors are immutable once built, and copy
+// gives every derived error a fresh cache.
+type statusCache struct {
+	once sync.Once
+	st   *status.Status
+}
+
 func (e *Er) GRPCStatus() *status.Status {
-	var code codes.Code
+	if e == nil {
+		return errNilPassed().GRPCStatus()
+	}
 
-	switch e.errCode {
-	case CodeInvalidInput, CodeValidation:
-		code = codes.InvalidArgument
-	case CodeNotFound:
-		code = codes.NotFound
-	case CodeAlreadyExists:
-		code = codes.AlreadyExists
-	case CodePermissionDenied:
-		code = codes.PermissionDenied
-	case CodeUnauthenticated:
-		code = codes.Unauthenticated
-	case CodeInternal:
-		code = codes.Internal
-	case CodeUnavailable:
-		code = codes.Unavailable
-	case CodeTimeout:
-		code = codes.DeadlineExceeded
-	case CodeResourceExhausted:
-		code = codes.ResourceExhausted
-	default:
+	if e.statusCache == nil {
+		return e.buildGRPCStatus(false)
+	}
+
+	e.statusCache.once.Do(
+		func() {
+			e.statusCache.st = e.buildGRPCStatus(false)
+		},
+	)
+	return e.statusCache.st
+}
+
+// GRPCStatusWithWrappedTypes is GRPCStatus with the Go type and message of
+// each wrapped error added to the DebugInfo detail. These may reveal internals,
+// so transports only send it when asked to include the stack trace. The
+// result is not cached.
+func (e *Er) GRPCStatusWithWrappedTypes() *status.Status {
+	if e == nil {
+		return errNilPassed().GRPCStatus()
+	}
+
+	return e.buildGRPCStatus(true)
+}
+
+func (e *Er) buildGRPCStatus(includeWrappedTypes bool) *status.Status {
+	code, ok := grpcCodeForCode(e.errCode)
+	if !ok {
 		code = codes.Unknown
 	}
+	if e.grpcCode != codes.OK {
+		code = e.grpcCode
+	}
 
 	msg := e.message
 	st := status.New(code, msg)
@@ -46,15 +72,43 @@ func (e *Er) GRPCStatus() *status.Status {
 		for _, ve := range e.validationErrors {
 			br.FieldViolations = append(
 				br.FieldViolations, &errdetails.BadRequest_FieldViolation{
-					Field:       ve.Field,
+					Field:       transformValidationField(ve.Field),
 					Description: ve.Message,
+					Reason:      ve.Code,
 				},
 			)
 		}
 		details = append(details, br)
 	}
 
-	if e.detail != "" || e.message != "" {
+	if len(e.quotaViolations) > 0 {
+		qf := &errdetails.QuotaFailure{}
+		for _, qv := range e.quotaViolations {
+			qf.Violations = append(
+				qf.Violations, &errdetails.QuotaFailure_Violation{
+					Subject:     qv.Subject,
+					Description: qv.Description,
+				},
+			)
+		}
+		details = append(details, qf)
+	}
+
+	if len(e.preconditions) > 0 {
+		pf := &errdetails.PreconditionFailure{}
+		for _, pv := range e.preconditions {
+			pf.Violations = append(
+				pf.Violations, &errdetails.PreconditionFailure_Violation{
+					Type:        pv.Type,
+					Subject:     pv.Subject,
+					Description: pv.Description,
+				},
+			)
+		}
+		details = append(details, pf)
+	}
+
+	if e.detail != "" || e.message != "" || e.messageKey != "" || e.reason != "" || e.requestID != "" || e.traceID != "" || e.operation != "" {
 		ei := &errdetails.ErrorInfo{
 			Reason: string(e.errCode),
 			Domain: "???",
@@ -63,24 +117,58 @@ func (e *Er) GRPCStatus() *status.Status {
 				"message": e.message,
 			},
 		}
+		if e.reason != "" {
+			// The reason replaces the code in ErrorInfo.Reason, so the code
+			// moves to the metadata.
+			ei.Reason = e.reason
+			ei.Metadata["code"] = string(e.errCode)
+		}
+		if e.messageKey != "" {
+			ei.Metadata["message_key"] = e.messageKey
+		}
+		if e.requestID != "" {
+			ei.Metadata["request_id"] = e.requestID
+		}
+		if e.traceID != "" {
+			ei.Metadata["trace_id"] = e.traceID
+		}
+		if e.operation != "" {
+			ei.Metadata["operation"] = e.operation
+		}
 		details = append(details, ei)
 	}
 
-	if len(e.stackTrace) > 0 {
-		stackEntries := make([]string, 0, len(e.stackTrace))
-		for _, frame := range e.stackTrace {
-			stackEntries = append(stackEntries, fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function))
+	stackTrace := e.GetStackTrace()
+	stackEntries := make([]string, 0, len(stackTrace)+len(e.wrapped))
+	for _, frame := range stackTrace {
+		stackEntries = append(stackEntries, encodeStackEntry(frame))
+	}
+	if includeWrappedTypes {
+		// Go types of wrapped errors help debugging and share the stack trace's
+		// exposure rules, so they travel in DebugInfo rather than Help, even
+		// when no stack was captured.
+		for _, wrappedErr := range e.wrapped {
+			stackEntries = append(stackEntries, fmt.Sprintf("%s%T: %v", wrappedEntryPrefix, wrappedErr, wrappedErr))
 		}
+	}
 
+	if len(stackEntries) > 0 || e.fullStack != "" {
 		di := &errdetails.DebugInfo{
 			StackEntries: stackEntries,
-			Detail:       "Go stack trace",
+			Detail:       debugInfoDetail,
+		}
+		if e.fullStack != "" {
+			di.Detail = e.fullStack
 		}
 		details = append(details, di)
 	}
 
-	if len(e.wrapped) > 0 {
+	if len(e.wrapped) > 0 || e.hint != "" {
 		help := &errdetails.Help{}
+		if e.hint != "" {
+			// The hint is the only link without a URL.
+			help.Links = append(help.Links, &errdetails.Help_Link{Description: e.hint})
+		}
 		for i, wrappedErr := range e.wrapped {
 			help.Links = append(
 				help.Links, &errdetails.Help_Link{
@@ -103,6 +191,50 @@ func (e *Er) GRPCStatus() *status.Status {
 	return st
 }
 
+func grpcCodeForCode(code ErrorCode) (codes.Code, bool) {
+	if grpcCode, ok := grpcCodeOverride(code); ok {
+		return grpcCode, true
+	}
+
+	switch code {
+	case CodeInvalidInput, CodeValidation:
+		return codes.InvalidArgument, true
+	case CodeNotFound, CodeGone:
+		return codes.NotFound, true
+	case CodeAlreadyExists:
+		return codes.AlreadyExists, true
+	case CodePermissionDenied:
+		return codes.PermissionDenied, true
+	case CodeUnauthenticated:
+		return codes.Unauthenticated, true
+	case CodeInternal:
+		return codes.Internal, true
+	case CodeUnavailable:
+		return codes.Unavailable, true
+	case CodeTimeout:
+		return codes.DeadlineExceeded, true
+	case CodeResourceExhausted:
+		return codes.ResourceExhausted, true
+	case CodeCanceled:
+		return codes.Canceled, true
+	case CodeConflict:
+		return codes.Aborted, true
+	case CodeBadGateway:
+		return codes.Unavailable, true
+	case CodeGatewayTimeout:
+		return codes.DeadlineExceeded, true
+	case CodeNotImplemented, CodeMethodNotAllowed:
+		return codes.Unimplemented, true
+	case CodeUnknown:
+		return codes.Unknown, true
+	}
+
+	if mapping, ok := registeredCode(code); ok && mapping.GRPCCode != codes.OK {
+		return mapping.GRPCCode, true
+	}
+	return codes.OK, false
+}
+
 func FromGRPCStatus(st *status.Status) Error {
 	var code ErrorCode
 	switch st.Code() {
@@ -124,6 +256,12 @@ func FromGRPCStatus(st *status.Status) Error {
 		code = CodeTimeout
 	case codes.ResourceExhausted:
 		code = CodeResourceExhausted
+	case codes.Canceled:
+		code = CodeCanceled
+	case codes.Aborted:
+		code = CodeConflict
+	case codes.Unimplemented:
+		code = CodeNotImplemented
 	default:
 		code = CodeUnknown
 	}
@@ -131,32 +269,8 @@ func FromGRPCStatus(st *status.Status) Error {
 }
 
 func FromGRPCStatusWithDetails(st *status.Status) Error {
-	var code ErrorCode
-	switch st.Code() {
-	case codes.InvalidArgument:
-		code = CodeValidation
-	case codes.NotFound:
-		code = CodeNotFound
-	case codes.AlreadyExists:
-		code = CodeAlreadyExists
-	case codes.PermissionDenied:
-		code = CodePermissionDenied
-	case codes.Unauthenticated:
-		code = CodeUnauthenticated
-	case codes.Internal:
-		code = CodeInternal
-	case codes.Unavailable:
-		code = CodeUnavailable
-	case codes.DeadlineExceeded:
-		code = CodeTimeout
-	case codes.ResourceExhausted:
-		code = CodeResourceExhausted
-	default:
-		code = CodeUnknown
-	}
-
 	err := &Er{
-		errCode: code,
+		errCode: detailedCodeForGRPCCode(st.Code()),
 		message: st.Message(),
 	}
 
@@ -167,35 +281,73 @@ func FromGRPCStatusWithDetails(st *status.Status) Error {
 				err.validationErrors = append(
 					err.validationErrors, ValidationError{
 						Field:   fv.Field,
+						Code:    fv.Reason,
 						Message: fv.Description,
 					},
 				)
 			}
+		case *errdetails.QuotaFailure:
+			for _, v := range d.Violations {
+				err.quotaViolations = append(
+					err.quotaViolations, QuotaViolation{
+						Subject:     v.Subject,
+						Description: v.Description,
+					},
+				)
+			}
+		case *errdetails.PreconditionFailure:
+			for _, v := range d.Violations {
+				err.preconditions = append(
+					err.preconditions, PreconditionViolation{
+						Type:        v.Type,
+						Subject:     v.Subject,
+						Description: v.Description,
+					},
+				)
+			}
 		case *errdetails.ErrorInfo:
+			if code, ok := errorInfoCode(d); ok {
+				err.errCode = code
+			}
+			if _, exists := d.Metadata["code"]; exists {
+				err.reason = d.Reason
+			}
 			if detail, exists := d.Metadata["detail"]; exists {
 				err.detail = detail
 			}
 			if message, exists := d.Metadata["message"]; exists && err.message == "" {
 				err.message = message
 			}
+			if messageKey, exists := d.Metadata["message_key"]; exists {
+				err.messageKey = messageKey
+			}
+			if requestID, exists := d.Metadata["request_id"]; exists {
+				err.requestID = requestID
+			}
+			if traceID, exists := d.Metadata["trace_id"]; exists {
+				err.traceID = traceID
+			}
+			if operation, exists := d.Metadata["operation"]; exists {
+				err.operation = operation
+			}
 		case *errdetails.DebugInfo:
+			if d.Detail != debugInfoDetail {
+				err.fullStack = d.Detail
+			}
 			for _, entry := range d.StackEntries {
-				parts := strings.Split(entry, " ")
-				if len(parts) >= 2 {
-					fileLineparts := strings.Split(parts[0], ":")
-					if len(fileLineparts) >= 2 {
-						err.stackTrace = append(
-							err.stackTrace, StackFrame{
-								Function: parts[1],
-								File:     fileLineparts[0],
-								Line:     parseInt(fileLineparts[1]),
-							},
-						)
-					}
+				if strings.HasPrefix(entry, wrappedEntryPrefix) {
+					continue
+				}
+				if frame, ok := decodeStackEntry(entry); ok {
+					err.stackTrace = append(err.stackTrace, frame)
 				}
 			}
 		case *errdetails.Help:
 			for _, link := range d.Links {
+				if link.Url == "" {
+					err.hint = link.Description
+					continue
+				}
 				err.wrapped = append(err.wrapped, errors.New(link.Url))
 			}
 		}
@@ -204,6 +356,101 @@ func FromGRPCStatusWithDetails(st *status.Status) Error {
 	return err
 }
 
+// detailedCodeForGRPCCode is the mapping of FromGRPCStatusWithDetails, which
+// reads InvalidArgument as CodeValidation.
+func detailedCodeForGRPCCode(code codes.Code) ErrorCode {
+	switch code {
+	case codes.InvalidArgument:
+		return CodeValidation
+	case codes.NotFound:
+		return CodeNotFound
+	case codes.AlreadyExists:
+		return CodeAlreadyExists
+	case codes.PermissionDenied:
+		return CodePermissionDenied
+	case codes.Unauthenticated:
+		return CodeUnauthenticated
+	case codes.Internal:
+		return CodeInternal
+	case codes.Unavailable:
+		return CodeUnavailable
+	case codes.DeadlineExceeded:
+		return CodeTimeout
+	case codes.ResourceExhausted:
+		return CodeResourceExhausted
+	case codes.Canceled:
+		return CodeCanceled
+	case codes.Aborted:
+		return CodeConflict
+	case codes.Unimplemented:
+		return CodeNotImplemented
+	}
+	return CodeUnknown
+}
+
+// errorInfoCode returns the erz code carried by an ErrorInfo: the "code"
+// metadata when a reason is set, the reason otherwise.
+func errorInfoCode(ei *errdetails.ErrorInfo) (ErrorCode, bool) {
+	code := ErrorCode(ei.Reason)
+	if metadataCode, exists := ei.Metadata["code"]; exists {
+		code = ErrorCode(metadataCode)
+	}
+	return code, isKnownCode(code)
+}
+
+// statusErrorCode returns the code FromGRPCStatusWithDetails assigns to st.
+func statusErrorCode(st *status.Status) ErrorCode {
+	code := detailedCodeForGRPCCode(st.Code())
+	for _, detail := range st.Details() {
+		if ei, ok := detail.(*errdetails.ErrorInfo); ok {
+			if infoCode, ok := errorInfoCode(ei); ok {
+				code = infoCode
+			}
+		}
+	}
+	return code
+}
+
+// encodeStackEntry writes a frame as tab-separated file, line, function,
+// package and full path. Tabs don't occur in Go function names and practically
+// never in paths, so paths with spaces or colons round-trip unchanged.
+func encodeStackEntry(frame StackFrame) string {
+	return strings.Join(
+		[]string{frame.File, strconv.Itoa(frame.Line), frame.Function, frame.Package, frame.FullPath}, "\t",
+	)
+}
+
+// decodeStackEntry parses entries written by encodeStackEntry, and the
+// "file:line function" entries of older versions.
+func decodeStackEntry(entry string) (StackFrame, bool) {
+	if fields := strings.Split(entry, "\t"); len(fields) == 5 {
+		line, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return StackFrame{}, false
+		}
+		return StackFrame{
+			File:     fields[0],
+			Line:     line,
+			Function: fields[2],
+			Package:  fields[3],
+			FullPath: fields[4],
+		}, true
+	}
+
+	// Function names contain neither spaces nor colons, so split on the last
+	// of each.
+	space := strings.LastIndexByte(entry, ' ')
+	if space < 0 {
+		return StackFrame{}, false
+	}
+	location, function := entry[:space], entry[space+1:]
+	colon := strings.LastIndexByte(location, ':')
+	if colon < 0 {
+		return StackFrame{}, false
+	}
+	return StackFrame{File: location[:colon], Line: parseInt(location[colon+1:]), Function: function}, true
+}
+
 func parseInt(s string) int {
 	var result int
 	for _, char := range s {
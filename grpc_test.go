@@ -0,0 +1,93 @@
+package erz_test
+
+import (
+	"errors"
+	"github.com/intezya/erz"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"slices"
+	"testing"
+)
+
+var benchStatus *status.Status
+
+func benchGRPCError() erz.Error {
+	return erz.Validation("invalid request").
+		WithValidationError("email", "must be a valid email", "not-an-email").
+		WithValidationError("age", "must be positive", -1).
+		WithRequestID("req-1")
+}
+
+// BenchmarkGRPCStatus converts the same error repeatedly, as interceptors and
+// status.FromError do, and hits the memoized status after the first call.
+func BenchmarkGRPCStatus(b *testing.B) {
+	err := benchGRPCError()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchStatus = err.GRPCStatus()
+	}
+}
+
+// BenchmarkGRPCStatusUncached builds the status on every call. Copies start
+// with an empty cache.
+func BenchmarkGRPCStatusUncached(b *testing.B) {
+	err := benchGRPCError()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchStatus = err.WithRequestID("req-1").GRPCStatus()
+	}
+}
+
+func TestGRPCStatusCached(t *testing.T) {
+	err := benchGRPCError()
+
+	if err.GRPCStatus() != err.GRPCStatus() {
+		t.Error("GRPCStatus is rebuilt on every call")
+	}
+
+	derived := err.WithRequestID("req-2")
+	if derived.GRPCStatus() == err.GRPCStatus() {
+		t.Fatal("derived error shares the cached status")
+	}
+	if got := erz.FromGRPCStatusWithDetails(derived.GRPCStatus()).GetRequestID(); got != "req-2" {
+		t.Errorf("derived status carries request ID %q, want req-2", got)
+	}
+}
+
+func TestGRPCStatusWithWrappedTypesWithoutStack(t *testing.T) {
+	erz.SetStackTraceSampleRate(0)
+	defer erz.SetStackTraceSampleRate(1)
+
+	err := erz.Internal("internal error").WithWrapped(errors.New("connection refused"))
+	if len(err.GetStackTrace()) != 0 {
+		t.Fatal("stack trace captured although sampling is off")
+	}
+
+	if debugInfo := findDebugInfo(err.GRPCStatus()); debugInfo != nil {
+		t.Errorf("GRPCStatus has DebugInfo %v", debugInfo.StackEntries)
+	}
+
+	debugInfo := findDebugInfo(err.GRPCStatusWithWrappedTypes())
+	if debugInfo == nil {
+		t.Fatal("GRPCStatusWithWrappedTypes has no DebugInfo")
+	}
+	want := []string{"wrapped *errors.errorString: connection refused"}
+	if !slices.Equal(debugInfo.StackEntries, want) {
+		t.Errorf("StackEntries = %q, want %q", debugInfo.StackEntries, want)
+	}
+
+	if stack := erz.FromGRPCStatusWithDetails(err.GRPCStatusWithWrappedTypes()).GetStackTrace(); len(stack) != 0 {
+		t.Errorf("wrapped types parsed as %d stack frames", len(stack))
+	}
+}
+
+func findDebugInfo(st *status.Status) *errdetails.DebugInfo {
+	for _, detail := range st.Details() {
+		if debugInfo, ok := detail.(*errdetails.DebugInfo); ok {
+			return debugInfo
+		}
+	}
+	return nil
+}
@@ -1,58 +1,135 @@
 package erz
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+const StatusClientClosedRequest = 499
+
+// Envelope versions for HTTPOptions.EnvelopeVersion. In v2 validation errors
+// are grouped by field under "fields" instead of listed in "validation_errors".
+const (
+	EnvelopeV1 = "v1"
+	EnvelopeV2 = "v2"
+)
+
 type Marshal func(v interface{}) ([]byte, error)
 
+type ErrorLogger func(ctx context.Context, err Error)
+
 type HTTPResponse struct {
-	Success   bool               `json:"success"`
-	Error     *HTTPErrorResponse `json:"error,omitempty"`
-	Data      interface{}        `json:"data,omitempty"`
-	Meta      *HTTPResponseMeta  `json:"meta,omitempty"`
-	Timestamp time.Time          `json:"timestamp,omitempty"`
-	RequestID string             `json:"request_id,omitempty"`
-	TraceID   string             `json:"trace_id,omitempty"`
+	XMLName   xml.Name           `json:"-" xml:"response"`
+	Success   bool               `json:"success" xml:"success"`
+	Error     *HTTPErrorResponse `json:"error,omitempty" xml:"error,omitempty"`
+	Data      interface{}        `json:"data,omitempty" xml:"data,omitempty"`
+	Meta      *HTTPResponseMeta  `json:"meta,omitempty" xml:"meta,omitempty"`
+	Warnings  []Warning          `json:"warnings,omitempty" xml:"warning,omitempty"`
+	Timestamp time.Time          `json:"timestamp,omitempty" xml:"timestamp,omitempty"`
+	RequestID string             `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	TraceID   string             `json:"trace_id,omitempty" xml:"trace_id,omitempty"`
 }
 
 type HTTPErrorResponse struct {
-	Code             string                 `json:"code"`
-	Message          string                 `json:"message"`
-	Detail           string                 `json:"detail,omitempty"`
-	ValidationErrors []ValidationError      `json:"validation_errors,omitempty"`
-	StackTrace       []StackFrame           `json:"stack_trace,omitempty"`
-	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	Code             string                 `json:"code" xml:"code"`
+	Reason           string                 `json:"reason,omitempty" xml:"reason,omitempty"`
+	Message          string                 `json:"message" xml:"message"`
+	Detail           string                 `json:"detail,omitempty" xml:"detail,omitempty"`
+	Hint             string                 `json:"hint,omitempty" xml:"hint,omitempty"`
+	ValidationErrors []ValidationError      `json:"validation_errors,omitempty" xml:"validation_error,omitempty"`
+	Fields           FieldViolations        `json:"fields,omitempty" xml:"fields,omitempty"`
+	StackTrace       []StackFrame           `json:"stack_trace,omitempty" xml:"stack_frame,omitempty"`
+	FullStack        string                 `json:"full_stack,omitempty" xml:"full_stack,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty" xml:"-"`
+}
+
+// FieldViolations groups the validation errors of a v2 envelope by field. In
+// XML every field is a <field name="..."> element, as maps have no XML form.
+type FieldViolations map[string][]FieldViolation
+
+// FieldViolation is a validation error in a v2 envelope, where the field is
+// the key it is grouped under.
+type FieldViolation struct {
+	Code    string `json:"code,omitempty" xml:"code,omitempty"`
+	Message string `json:"message" xml:"message"`
+	Value   any    `json:"value,omitempty" xml:"value,omitempty"`
+}
+
+type Warning struct {
+	Code    string `json:"code" xml:"code"`
+	Message string `json:"message" xml:"message"`
 }
 
 type HTTPResponseMeta struct {
-	Version    string            `json:"version,omitempty"`
-	Pagination *PaginationMeta   `json:"pagination,omitempty"`
-	Headers    map[string]string `json:"headers,omitempty"`
+	Version    string            `json:"version,omitempty" xml:"version,omitempty"`
+	Pagination *PaginationMeta   `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty" xml:"-"`
 }
 
 type PaginationMeta struct {
-	Page       int  `json:"page"`
-	PerPage    int  `json:"per_page"`
-	Total      int  `json:"total"`
-	TotalPages int  `json:"total_pages"`
-	HasNext    bool `json:"has_next"`
-	HasPrev    bool `json:"has_prev"`
+	Page       int    `json:"page,omitempty" xml:"page,omitempty"`
+	PerPage    int    `json:"per_page,omitempty" xml:"per_page,omitempty"`
+	Total      int    `json:"total,omitempty" xml:"total,omitempty"`
+	TotalPages int    `json:"total_pages,omitempty" xml:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty" xml:"prev_cursor,omitempty"`
+	HasNext    bool   `json:"has_next" xml:"has_next"`
+	HasPrev    bool   `json:"has_prev" xml:"has_prev"`
 }
 
 type HTTPOptions struct {
 	IncludeStackTrace bool
 	IncludeTimestamp  bool
+	Redact            bool
+	Language          string
 	RequestID         string
 	TraceID           string
-	Version           string
-	Metadata          map[string]interface{}
-	Marshal           Marshal
+	// Deprecated: meta.version reports EnvelopeVersion; Version is ignored.
+	Version              string
+	Metadata             map[string]interface{}
+	Marshal              Marshal
+	Serializer           Serializer
+	GenerateRequestID    bool
+	SortValidationErrors bool
+	// EnvelopeVersion selects the response shape, EnvelopeV1 (the default) or
+	// EnvelopeV2. It is always reported as meta.version.
+	EnvelopeVersion string
+	// ErrorLogger is called with the full error right before it is written by
+	// DefaultHTTPErrorHandler and the framework integrations.
+	ErrorLogger ErrorLogger
+}
+
+var defaultHTTPOptions atomic.Pointer[HTTPOptions]
+
+// SetDefaultHTTPOptions replaces the options returned by DefaultHTTPOptions,
+// which every function uses when it is passed nil options. The options are
+// copied. Passing nil restores the built-in defaults.
+func SetDefaultHTTPOptions(opts *HTTPOptions) {
+	if opts == nil {
+		defaultHTTPOptions.Store(nil)
+		return
+	}
+
+	defaults := *opts
+	if defaults.Marshal == nil {
+		defaults.Marshal = json.Marshal
+	}
+	defaultHTTPOptions.Store(&defaults)
 }
 
+// DefaultHTTPOptions returns a copy of the options set with
+// SetDefaultHTTPOptions, or the built-in defaults.
 func DefaultHTTPOptions() *HTTPOptions {
+	if defaults := defaultHTTPOptions.Load(); defaults != nil {
+		opts := *defaults
+		return &opts
+	}
+
 	return &HTTPOptions{
 		IncludeStackTrace: false,
 		IncludeTimestamp:  true,
@@ -61,45 +138,101 @@ func DefaultHTTPOptions() *HTTPOptions {
 }
 
 func (e *Er) HTTPStatus() int {
-	switch e.errCode {
+	if e == nil {
+		return http.StatusInternalServerError
+	}
+
+	if e.httpStatus != 0 {
+		return e.httpStatus
+	}
+
+	if status, ok := httpStatusForCode(e.errCode); ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+func httpStatusForCode(code ErrorCode) (int, bool) {
+	if status, ok := httpStatusOverride(code); ok {
+		return status, true
+	}
+
+	switch code {
 	case CodeInvalidInput, CodeValidation:
-		return http.StatusBadRequest
+		return http.StatusBadRequest, true
 	case CodeNotFound:
-		return http.StatusNotFound
-	case CodeAlreadyExists:
-		return http.StatusConflict
+		return http.StatusNotFound, true
+	case CodeAlreadyExists, CodeConflict:
+		return http.StatusConflict, true
 	case CodePermissionDenied:
-		return http.StatusForbidden
+		return http.StatusForbidden, true
 	case CodeUnauthenticated:
-		return http.StatusUnauthorized
-	case CodeInternal:
-		return http.StatusInternalServerError
+		return http.StatusUnauthorized, true
+	case CodeUnknown, CodeInternal:
+		return http.StatusInternalServerError, true
 	case CodeUnavailable:
-		return http.StatusServiceUnavailable
+		return http.StatusServiceUnavailable, true
 	case CodeTimeout:
-		return http.StatusRequestTimeout
+		return http.StatusRequestTimeout, true
 	case CodeResourceExhausted:
-		return http.StatusTooManyRequests
-	default:
-		return http.StatusInternalServerError
+		return http.StatusTooManyRequests, true
+	case CodeCanceled:
+		return StatusClientClosedRequest, true
+	case CodeBadGateway:
+		return http.StatusBadGateway, true
+	case CodeGatewayTimeout:
+		return http.StatusGatewayTimeout, true
+	case CodeNotImplemented:
+		return http.StatusNotImplemented, true
+	case CodeMethodNotAllowed:
+		return http.StatusMethodNotAllowed, true
+	case CodeGone:
+		return http.StatusGone, true
 	}
+
+	if mapping, ok := registeredCode(code); ok && mapping.HTTPStatus != 0 {
+		return mapping.HTTPStatus, true
+	}
+	return 0, false
 }
 
 func (e *Er) ToHTTPResponse(options *HTTPOptions) *HTTPResponse {
+	if e == nil {
+		return errNilPassed().ToHTTPResponse(options)
+	}
+
 	if options == nil {
 		options = DefaultHTTPOptions()
 	}
 
+	if options.Redact {
+		e = e.redacted(options.Language)
+	}
+
 	errorResp := &HTTPErrorResponse{
 		Code:             string(e.errCode),
+		Reason:           e.reason,
 		Message:          e.message,
 		Detail:           e.detail,
-		ValidationErrors: e.validationErrors,
-		Metadata:         options.Metadata,
+		Hint:             e.hint,
+		ValidationErrors: sanitizeValidationErrors(LocalizeValidationErrors(e.validationErrors, options.Language)),
+		Metadata:         e.httpMetadata(options),
+	}
+
+	if options.SortValidationErrors {
+		errorResp.ValidationErrors = SortValidationErrors(errorResp.ValidationErrors)
 	}
 
-	if options.IncludeStackTrace && len(e.stackTrace) > 0 {
-		errorResp.StackTrace = e.stackTrace
+	if options.EnvelopeVersion == EnvelopeV2 {
+		errorResp.Fields = groupValidationErrors(errorResp.ValidationErrors)
+		errorResp.ValidationErrors = nil
+	}
+
+	if options.IncludeStackTrace {
+		if stackTrace := e.GetStackTrace(); len(stackTrace) > 0 {
+			errorResp.StackTrace = stackTrace
+		}
+		errorResp.FullStack = e.fullStack
 	}
 
 	response := &HTTPResponse{
@@ -108,43 +241,145 @@ func (e *Er) ToHTTPResponse(options *HTTPOptions) *HTTPResponse {
 	}
 
 	if options.IncludeTimestamp {
-		response.Timestamp = time.Now().UTC()
+		response.Timestamp = now().UTC()
+		if !e.timestamp.IsZero() {
+			response.Timestamp = e.timestamp.UTC()
+		}
 	}
 
+	response.RequestID = e.requestID
 	if options.RequestID != "" {
 		response.RequestID = options.RequestID
 	}
 
+	response.TraceID = e.traceID
 	if options.TraceID != "" {
 		response.TraceID = options.TraceID
 	}
 
-	if options.Version != "" {
-		if response.Meta == nil {
-			response.Meta = &HTTPResponseMeta{}
-		}
-		response.Meta.Version = options.Version
-	}
+	response.Meta = &HTTPResponseMeta{Version: options.metaVersion()}
+
+	response.Meta.Headers = e.responseHeaders()
 
 	return response
 }
 
+func (o *HTTPOptions) metaVersion() string {
+	if o.EnvelopeVersion == "" {
+		return EnvelopeV1
+	}
+	return o.EnvelopeVersion
+}
+
+func groupValidationErrors(errs []ValidationError) FieldViolations {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	fields := make(FieldViolations)
+	for _, ve := range errs {
+		fields[ve.Field] = append(
+			fields[ve.Field], FieldViolation{
+				Code:    ve.Code,
+				Message: ve.Message,
+				Value:   ve.Value,
+			},
+		)
+	}
+	return fields
+}
+
+func (e *Er) responseHeaders() map[string]string {
+	if e.cacheControl == "" && len(e.allowedMethods) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, 2)
+	if e.cacheControl != "" {
+		headers["Cache-Control"] = e.cacheControl
+	}
+	if len(e.allowedMethods) > 0 {
+		headers["Allow"] = strings.Join(e.allowedMethods, ", ")
+	}
+	return headers
+}
+
+func setErrorHeaders(h http.Header, err Error) {
+	if cacheControl := err.GetCacheControl(); cacheControl != "" {
+		h.Set("Cache-Control", cacheControl)
+	}
+	if methods := err.GetAllowedMethods(); len(methods) > 0 {
+		h.Set("Allow", strings.Join(methods, ", "))
+	}
+}
+
+func (e *Er) httpMetadata(options *HTTPOptions) map[string]interface{} {
+	if e.messageKey == "" && e.operation == "" && len(e.preconditions) == 0 {
+		return options.Metadata
+	}
+
+	metadata := make(map[string]interface{}, len(options.Metadata)+3)
+	for k, v := range options.Metadata {
+		metadata[k] = v
+	}
+	if e.messageKey != "" {
+		metadata["message_key"] = e.messageKey
+	}
+	if e.operation != "" {
+		metadata["operation"] = e.operation
+	}
+	if len(e.preconditions) > 0 {
+		metadata["precondition_violations"] = e.preconditions
+	}
+
+	return metadata
+}
+
 func (e *Er) AsJSON(options *HTTPOptions) []byte {
+	bytes, _ := e.ToJSON(options)
+	return bytes
+}
+
+// ToJSON serializes the full response envelope according to options. Unlike
+// MarshalJSON it can include internal fields such as the detail and stack trace.
+func (e *Er) ToJSON(options *HTTPOptions) ([]byte, error) {
+	if e == nil {
+		return errNilPassed().ToJSON(options)
+	}
+
 	if options == nil {
 		options = DefaultHTTPOptions()
 	}
 
-	response := e.ToHTTPResponse(options)
+	return options.marshal(e.ToHTTPResponse(options))
+}
 
-	bytes, _ := options.Marshal(response)
-	return bytes
+// MarshalJSON serializes only the public view of the error (code, public
+// message and validation errors), so marshaling an error directly never leaks
+// internal details. Use ToJSON for the full envelope.
+func (e *Er) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		&HTTPErrorResponse{
+			Code:             string(e.errCode),
+			Reason:           e.reason,
+			Message:          e.PublicError(),
+			Hint:             e.hint,
+			ValidationErrors: sanitizeValidationErrors(e.validationErrors),
+		},
+	)
 }
 
 func FromHTTPStatus(status int, message string) Error {
+	if code, ok := codeForHTTPStatusOverride(status); ok {
+		return New(code, message)
+	}
+
 	var code ErrorCode
 	switch status {
 	case http.StatusBadRequest:
 		code = CodeInvalidInput
+	case http.StatusUnprocessableEntity:
+		code = CodeValidation
 	case http.StatusNotFound:
 		code = CodeNotFound
 	case http.StatusConflict:
@@ -161,12 +396,73 @@ func FromHTTPStatus(status int, message string) Error {
 		code = CodeResourceExhausted
 	case http.StatusInternalServerError:
 		code = CodeInternal
+	case StatusClientClosedRequest:
+		code = CodeCanceled
+	case http.StatusBadGateway:
+		code = CodeBadGateway
+	case http.StatusGatewayTimeout:
+		code = CodeGatewayTimeout
+	case http.StatusNotImplemented:
+		code = CodeNotImplemented
+	case http.StatusMethodNotAllowed:
+		code = CodeMethodNotAllowed
+	case http.StatusGone:
+		code = CodeGone
 	default:
 		code = CodeUnknown
 	}
 	return New(code, message)
 }
 
+func FromHTTPStatusWithConflict(status int, message string) Error {
+	if status == http.StatusConflict {
+		return New(CodeConflict, message)
+	}
+	return FromHTTPStatus(status, message)
+}
+
+func WriteHTTPError(w http.ResponseWriter, err Error, options *HTTPOptions) error {
+	err = orNilPassed(err)
+
+	if options == nil {
+		options = DefaultHTTPOptions()
+	}
+
+	body, contentType, marshalErr := options.Encode(err.ToHTTPResponse(options))
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	setErrorHeaders(w.Header(), err)
+	w.WriteHeader(err.HTTPStatus())
+	_, writeErr := w.Write(body)
+	return writeErr
+}
+
+func WriteSuccessResponse(w http.ResponseWriter, data interface{}, options *HTTPOptions) error {
+	return WriteHTTPResponse(w, CreateSuccessResponse(data, options), options)
+}
+
+// WriteHTTPResponse writes a success response built with CreateSuccessResponse,
+// e.g. one carrying warnings added with AddWarning or pagination, with status
+// 200.
+func WriteHTTPResponse(w http.ResponseWriter, response *HTTPResponse, options *HTTPOptions) error {
+	if options == nil {
+		options = DefaultHTTPOptions()
+	}
+
+	body, contentType, marshalErr := options.Encode(response)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, writeErr := w.Write(body)
+	return writeErr
+}
+
 func CreateSuccessResponse(data interface{}, options *HTTPOptions) *HTTPResponse {
 	if options == nil {
 		options = DefaultHTTPOptions()
@@ -178,7 +474,7 @@ func CreateSuccessResponse(data interface{}, options *HTTPOptions) *HTTPResponse
 	}
 
 	if options.IncludeTimestamp {
-		response.Timestamp = time.Now().UTC()
+		response.Timestamp = now().UTC()
 	}
 
 	if options.RequestID != "" {
@@ -189,16 +485,13 @@ func CreateSuccessResponse(data interface{}, options *HTTPOptions) *HTTPResponse
 		response.TraceID = options.TraceID
 	}
 
-	if options.Version != "" {
-		if response.Meta == nil {
-			response.Meta = &HTTPResponseMeta{}
-		}
-		response.Meta.Version = options.Version
-	}
+	response.Meta = &HTTPResponseMeta{Version: options.metaVersion()}
 
 	return response
 }
 
+// WithPagination sets offset-based pagination. It replaces any cursor-based
+// pagination previously set with WithCursorPagination.
 func (r *HTTPResponse) WithPagination(page, perPage, total int) *HTTPResponse {
 	if r.Meta == nil {
 		r.Meta = &HTTPResponseMeta{}
@@ -221,6 +514,33 @@ func (r *HTTPResponse) WithPagination(page, perPage, total int) *HTTPResponse {
 	return r
 }
 
+// WithCursorPagination sets cursor-based pagination. It replaces any
+// offset-based pagination previously set with WithPagination.
+func (r *HTTPResponse) WithCursorPagination(next, prev string, hasNext, hasPrev bool) *HTTPResponse {
+	if r.Meta == nil {
+		r.Meta = &HTTPResponseMeta{}
+	}
+
+	r.Meta.Pagination = &PaginationMeta{
+		NextCursor: next,
+		PrevCursor: prev,
+		HasNext:    hasNext,
+		HasPrev:    hasPrev,
+	}
+
+	return r
+}
+
+func (r *HTTPResponse) AddWarning(code ErrorCode, message string) *HTTPResponse {
+	r.Warnings = append(
+		r.Warnings, Warning{
+			Code:    string(code),
+			Message: message,
+		},
+	)
+	return r
+}
+
 func (r *HTTPResponse) WithHeaders(headers map[string]string) *HTTPResponse {
 	if r.Meta == nil {
 		r.Meta = &HTTPResponseMeta{}
@@ -234,6 +554,6 @@ func (r *HTTPResponse) AsJSON(options *HTTPOptions) []byte {
 		options = DefaultHTTPOptions()
 	}
 
-	bytes, _ := options.Marshal(r)
+	bytes, _ := options.marshal(r)
 	return bytes
 }
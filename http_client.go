@@ -0,0 +1,131 @@
+package erz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+type problemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+}
+
+// FromHTTPResponse converts a non-2xx response into an Error. The body is read
+// fully and replaced with an in-memory reader, so it can still be consumed by
+// the caller afterwards. A nil Error is returned for 2xx responses.
+func FromHTTPResponse(resp *http.Response) (Error, error) {
+	if resp == nil {
+		return nil, errors.New("nil http response")
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		var err error
+		body, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil, nil
+	}
+
+	return FromHTTPResponseBody(resp.StatusCode, body), nil
+}
+
+// FromHTTPResponseBody builds an Error from a response status and body. It
+// understands the erz envelope and RFC 7807 problem+json bodies. Anything else
+// is mapped with FromHTTPStatus and the upstream body is kept as the detail.
+func FromHTTPResponseBody(status int, body []byte) Error {
+	if len(body) > 0 {
+		var envelope HTTPResponse
+		if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != nil && envelope.Error.Code != "" {
+			return fromHTTPEnvelope(&envelope)
+		}
+
+		var problem problemDetails
+		if err := json.Unmarshal(body, &problem); err == nil && (problem.Title != "" || problem.Detail != "") {
+			err := FromHTTPStatus(status, problem.Title)
+			if problem.Detail != "" {
+				err = err.WithDetail(problem.Detail)
+			}
+			return err
+		}
+	}
+
+	err := FromHTTPStatus(status, http.StatusText(status))
+	if upstreamBody := bytes.TrimSpace(body); len(upstreamBody) > 0 {
+		err = err.WithDetail(string(upstreamBody))
+	}
+	return err
+}
+
+// ParseHTTPError decodes an erz response envelope. It returns a nil Error for
+// successful responses and fails if an unsuccessful response carries no error
+// object.
+func ParseHTTPError(data []byte) (Error, error) {
+	var envelope HTTPResponse
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+
+	if envelope.Success {
+		return nil, nil
+	}
+
+	if envelope.Error == nil {
+		return nil, errors.New("response has no error object")
+	}
+
+	return fromHTTPEnvelope(&envelope), nil
+}
+
+func fromHTTPEnvelope(envelope *HTTPResponse) *Er {
+	resp := envelope.Error
+	err := &Er{
+		errCode:          ErrorCode(resp.Code),
+		reason:           resp.Reason,
+		message:          resp.Message,
+		detail:           resp.Detail,
+		hint:             resp.Hint,
+		validationErrors: resp.ValidationErrors,
+		stackTrace:       resp.StackTrace,
+		fullStack:        resp.FullStack,
+		requestID:        envelope.RequestID,
+		traceID:          envelope.TraceID,
+		timestamp:        envelope.Timestamp,
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(resp.Fields)) {
+		for _, fv := range resp.Fields[field] {
+			err.validationErrors = append(
+				err.validationErrors, ValidationError{
+					Field:   field,
+					Code:    fv.Code,
+					Message: fv.Message,
+					Value:   fv.Value,
+				},
+			)
+		}
+	}
+
+	if key, ok := resp.Metadata["message_key"].(string); ok {
+		err.messageKey = key
+	}
+	if operation, ok := resp.Metadata["operation"].(string); ok {
+		err.operation = operation
+	}
+
+	return err
+}
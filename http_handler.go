@@ -0,0 +1,90 @@
+package erz
+
+import (
+	"net/http"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+	HeaderTraceID   = "X-Trace-ID"
+)
+
+type HandlerFunc func(http.ResponseWriter, *http.Request) error
+
+// Handler adapts an error-returning handler to http.Handler. Returned errors
+// are written with DefaultHTTPErrorHandler.
+func Handler(h HandlerFunc, opts *HTTPOptions) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if err := h(w, r); err != nil {
+				DefaultHTTPErrorHandler(w, r, err, opts)
+			}
+		},
+	)
+}
+
+// HTTPMiddleware recovers panics in the wrapped handler and writes them as
+// CodeInternal errors.
+func HTTPMiddleware(opts *HTTPOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						DefaultHTTPErrorHandler(w, r, RecoverToError(recovered), opts)
+					}
+				}()
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// DefaultHTTPErrorHandler converts err with FromError and writes it. Request
+// and trace IDs are taken from the X-Request-ID and X-Trace-ID headers and the
+// language from Accept-Language, unless opts already sets them. Without a
+// Serializer in opts the response format is negotiated from the Accept header.
+// With GenerateRequestID a request ID is generated and echoed in the
+// X-Request-ID response header when neither the request nor err carries one. If opts is nil, the options stored in the
+// request context by ContextWithOptions are used.
+func DefaultHTTPErrorHandler(w http.ResponseWriter, r *http.Request, err error, opts *HTTPOptions) {
+	erzErr := orNilPassed(FromError(err))
+
+	requestOpts := requestHTTPOptions(r, opts)
+	if requestOpts.RequestID == "" && erzErr.GetRequestID() == "" && requestOpts.GenerateRequestID {
+		requestOpts.RequestID = generateRequestID()
+		w.Header().Set(HeaderRequestID, requestOpts.RequestID)
+	}
+	if requestOpts.Serializer == nil {
+		// JSON keeps using opts.Marshal so custom JSON encoders still apply.
+		if mediaType, serializer := NegotiateSerializer(r.Header.Get("Accept")); mediaType != MediaTypeJSON {
+			requestOpts.Serializer = serializer
+		}
+	}
+
+	if requestOpts.ErrorLogger != nil {
+		requestOpts.ErrorLogger(r.Context(), erzErr)
+	}
+
+	_ = WriteHTTPError(w, erzErr, requestOpts)
+}
+
+func requestHTTPOptions(r *http.Request, opts *HTTPOptions) *HTTPOptions {
+	if opts == nil {
+		opts = OptionsFromContext(r.Context())
+	}
+
+	requestOpts := *opts
+	if requestOpts.RequestID == "" {
+		requestOpts.RequestID = r.Header.Get(HeaderRequestID)
+	}
+	if requestOpts.TraceID == "" {
+		requestOpts.TraceID = r.Header.Get(HeaderTraceID)
+	}
+	if requestOpts.Language == "" {
+		requestOpts.Language = ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	return &requestOpts
+}
@@ -0,0 +1,87 @@
+package erz
+
+import (
+	"bufio"
+	"bytes"
+	"iter"
+	"net/http"
+)
+
+// WriteHTTPErrorStream writes err like WriteHTTPError, but encodes validation
+// errors one at a time: first those carried by err, then those yielded by
+// validationErrors, which may be nil. Large batches, e.g. from a bulk import,
+// can therefore be produced lazily without building the whole response in
+// memory. The response is always JSON encoded with options.Marshal, or
+// encoding/json if it is nil, and is a v1 envelope whatever the
+// EnvelopeVersion. An error while streaming leaves the response truncated, as
+// the status has already been sent.
+func WriteHTTPErrorStream(w http.ResponseWriter, err Error, validationErrors iter.Seq[ValidationError], options *HTTPOptions) error {
+	err = orNilPassed(err)
+
+	if options == nil {
+		options = DefaultHTTPOptions()
+	}
+
+	// Validation errors are always listed, so the envelope is a v1 one.
+	v1Options := *options
+	v1Options.EnvelopeVersion = EnvelopeV1
+	options = &v1Options
+
+	resp := err.ToHTTPResponse(options)
+	errorResp := resp.Error
+	resp.Error = nil
+	errorResp.ValidationErrors = nil
+
+	envelope, marshalErr := options.marshalJSON(resp)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	errorObject, marshalErr := options.marshalJSON(errorResp)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	w.Header().Set("Content-Type", MediaTypeJSON)
+	setErrorHeaders(w.Header(), err)
+	w.WriteHeader(err.HTTPStatus())
+
+	bw := bufio.NewWriter(w)
+
+	// {"error":{...,"validation_errors":[...]},<rest of the envelope>}
+	bw.WriteString(`{"error":`)
+	bw.Write(bytes.TrimSuffix(errorObject, []byte("}")))
+	bw.WriteString(`,"validation_errors":[`)
+
+	first := true
+	writeValidationError := func(ve ValidationError) error {
+		ve = sanitizeValidationErrors(LocalizeValidationErrors([]ValidationError{ve}, options.Language))[0]
+		encoded, encodeErr := options.marshalJSON(ve)
+		if encodeErr != nil {
+			return encodeErr
+		}
+		if !first {
+			bw.WriteByte(',')
+		}
+		first = false
+		_, writeErr := bw.Write(encoded)
+		return writeErr
+	}
+
+	for _, ve := range err.GetValidationErrors() {
+		if writeErr := writeValidationError(ve); writeErr != nil {
+			return writeErr
+		}
+	}
+	if validationErrors != nil {
+		for ve := range validationErrors {
+			if writeErr := writeValidationError(ve); writeErr != nil {
+				return writeErr
+			}
+		}
+	}
+
+	bw.WriteString(`]},`)
+	bw.Write(bytes.TrimPrefix(envelope, []byte("{")))
+
+	return bw.Flush()
+}
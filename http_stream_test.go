@@ -0,0 +1,99 @@
+package erz_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/intezya/erz"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const benchValidationErrors = 1000
+
+func benchValidationSeq(yield func(erz.ValidationError) bool) {
+	for i := 0; i < benchValidationErrors; i++ {
+		if !yield(erz.ValidationError{Field: "items[" + strconv.Itoa(i) + "].price", Message: "must be positive", Value: -i}) {
+			return
+		}
+	}
+}
+
+// discardResponseWriter drops the body so benchmarks measure the encoder
+// rather than a buffer holding the response.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+func BenchmarkWriteHTTPError(b *testing.B) {
+	w := &discardResponseWriter{header: http.Header{}}
+	opts := erz.DefaultHTTPOptions()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var validationErrors []erz.ValidationError
+		for ve := range benchValidationSeq {
+			validationErrors = append(validationErrors, ve)
+		}
+		_ = erz.WriteHTTPError(w, erz.ValidationWithErrors("invalid items", validationErrors), opts)
+	}
+}
+
+func BenchmarkWriteHTTPErrorStream(b *testing.B) {
+	w := &discardResponseWriter{header: http.Header{}}
+	opts := erz.DefaultHTTPOptions()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = erz.WriteHTTPErrorStream(w, erz.Validation("invalid items"), benchValidationSeq, opts)
+	}
+}
+
+func TestWriteHTTPErrorStreamMatchesWriteHTTPError(t *testing.T) {
+	erz.SetClock(func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) })
+	defer erz.SetClock(nil)
+
+	var validationErrors []erz.ValidationError
+	for ve := range benchValidationSeq {
+		validationErrors = append(validationErrors, ve)
+	}
+
+	buffered := httptest.NewRecorder()
+	if err := erz.WriteHTTPError(buffered, erz.ValidationWithErrors("invalid items", validationErrors), nil); err != nil {
+		t.Fatal(err)
+	}
+	streamed := httptest.NewRecorder()
+	if err := erz.WriteHTTPErrorStream(streamed, erz.Validation("invalid items"), benchValidationSeq, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if buffered.Code != streamed.Code {
+		t.Errorf("status %d, want %d", streamed.Code, buffered.Code)
+	}
+	if !json.Valid(streamed.Body.Bytes()) {
+		t.Fatalf("streamed body is not valid JSON: %s", streamed.Body)
+	}
+	if !bytes.Equal(compactJSON(t, buffered.Body.Bytes()), compactJSON(t, streamed.Body.Bytes())) {
+		t.Errorf("streamed body differs:\n%s\nwant:\n%s", streamed.Body, buffered.Body)
+	}
+}
+
+func compactJSON(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
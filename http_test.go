@@ -0,0 +1,314 @@
+package erz_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/intezya/erz"
+	"google.golang.org/grpc/codes"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var nilErrors = map[string]erz.Error{
+	"nil":       nil,
+	"typed nil": (*erz.Er)(nil),
+}
+
+func assertNilPassedResponse(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	var resp erz.HTTPResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid body %q: %v", rec.Body, err)
+	}
+	if resp.Error == nil || resp.Error.Code != string(erz.CodeInternal) {
+		t.Errorf("body %s, want an INTERNAL error", rec.Body)
+	}
+}
+
+func TestWriteHTTPErrorNil(t *testing.T) {
+	for name, err := range nilErrors {
+		t.Run(
+			name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				if writeErr := erz.WriteHTTPError(rec, err, nil); writeErr != nil {
+					t.Fatal(writeErr)
+				}
+				assertNilPassedResponse(t, rec)
+			},
+		)
+	}
+}
+
+func TestWriteHTTPErrorStreamNil(t *testing.T) {
+	for name, err := range nilErrors {
+		t.Run(
+			name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				if writeErr := erz.WriteHTTPErrorStream(rec, err, nil, nil); writeErr != nil {
+					t.Fatal(writeErr)
+				}
+				assertNilPassedResponse(t, rec)
+			},
+		)
+	}
+}
+
+func TestDefaultHTTPErrorHandlerNil(t *testing.T) {
+	errs := map[string]error{
+		"typed nil":         (*erz.Er)(nil),
+		"wrapped typed nil": errors.Join((*erz.Er)(nil)),
+	}
+	for name, err := range errs {
+		t.Run(
+			name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				erz.DefaultHTTPErrorHandler(rec, httptest.NewRequest(http.MethodGet, "/", nil), err, nil)
+				assertNilPassedResponse(t, rec)
+			},
+		)
+	}
+}
+
+func TestNilErMethods(t *testing.T) {
+	var err *erz.Er
+
+	if got := err.Error(); got == "" {
+		t.Error("Error() on a nil *Er is empty")
+	}
+	if got := err.HTTPStatus(); got != http.StatusInternalServerError {
+		t.Errorf("HTTPStatus() = %d, want %d", got, http.StatusInternalServerError)
+	}
+	if got := err.GRPCStatus().Code(); got != codes.Internal {
+		t.Errorf("GRPCStatus().Code() = %v, want %v", got, codes.Internal)
+	}
+	if resp := err.ToHTTPResponse(nil); resp.Error == nil || resp.Error.Code != string(erz.CodeInternal) {
+		t.Errorf("ToHTTPResponse(nil) = %+v, want an INTERNAL error", resp)
+	}
+}
+
+func TestWritersWithZeroOptions(t *testing.T) {
+	t.Run(
+		"WriteHTTPError", func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := erz.WriteHTTPError(rec, erz.NotFound("user"), &erz.HTTPOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			assertJSONResponse(t, rec, http.StatusNotFound)
+		},
+	)
+	t.Run(
+		"WriteHTTPErrorStream", func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := erz.WriteHTTPErrorStream(rec, erz.Validation("invalid"), nil, &erz.HTTPOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			assertJSONResponse(t, rec, http.StatusBadRequest)
+		},
+	)
+	t.Run(
+		"WriteBatchResponse", func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			results := []erz.BatchResult{erz.BatchSuccess(0, "ok"), erz.BatchFailure(1, erz.NotFound("user"), &erz.HTTPOptions{})}
+			if err := erz.WriteBatchResponse(rec, results, &erz.HTTPOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			assertJSONResponse(t, rec, http.StatusMultiStatus)
+		},
+	)
+	t.Run(
+		"DefaultHTTPErrorHandler", func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			erz.DefaultHTTPErrorHandler(rec, r, erz.NotFound("user"), &erz.HTTPOptions{GenerateRequestID: true})
+			assertJSONResponse(t, rec, http.StatusNotFound)
+		},
+	)
+}
+
+func assertJSONResponse(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Errorf("status %d, want %d", rec.Code, status)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != erz.MediaTypeJSON {
+		t.Errorf("Content-Type %q, want %q", contentType, erz.MediaTypeJSON)
+	}
+	if !json.Valid(rec.Body.Bytes()) {
+		t.Errorf("invalid JSON body %q", rec.Body)
+	}
+}
+
+func TestEnvelopeVersions(t *testing.T) {
+	err := erz.Validation("validation failed").
+		WithValidationError("email", "is required", nil).
+		WithValidationError("email", "must be an email", nil)
+
+	tests := []struct {
+		name    string
+		options *erz.HTTPOptions
+		want    string
+	}{
+		{
+			name:    "default",
+			options: &erz.HTTPOptions{},
+			want:    `{"success":false,"error":{"code":"VALIDATION","message":"validation failed","validation_errors":[{"field":"email","message":"is required"},{"field":"email","message":"must be an email"}]},"meta":{"version":"v1"},"timestamp":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:    "v2",
+			options: &erz.HTTPOptions{EnvelopeVersion: erz.EnvelopeV2},
+			want:    `{"success":false,"error":{"code":"VALIDATION","message":"validation failed","fields":{"email":[{"message":"is required"},{"message":"must be an email"}]}},"meta":{"version":"v2"},"timestamp":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:    "v2 ignores Version",
+			options: &erz.HTTPOptions{EnvelopeVersion: erz.EnvelopeV2, Version: "2024-01-01"},
+			want:    `{"success":false,"error":{"code":"VALIDATION","message":"validation failed","fields":{"email":[{"message":"is required"},{"message":"must be an email"}]}},"meta":{"version":"v2"},"timestamp":"0001-01-01T00:00:00Z"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, marshalErr := err.ToJSON(tt.options)
+				if marshalErr != nil {
+					t.Fatal(marshalErr)
+				}
+				if string(got) != tt.want {
+					t.Errorf("got  %s\nwant %s", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestEnvelopeV2XML(t *testing.T) {
+	err := erz.Validation("validation failed").
+		WithValidationError("name", "is too short", "a").
+		WithValidationError("email", "is required", nil)
+
+	body, marshalErr := err.ToJSON(&erz.HTTPOptions{EnvelopeVersion: erz.EnvelopeV2, Serializer: erz.XMLSerializer{}})
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+
+	want := `<fields><field name="email"><violation><message>is required</message></violation></field>` +
+		`<field name="name"><violation><message>is too short</message><value>a</value></violation></field></fields>`
+	if !strings.Contains(string(body), want) {
+		t.Errorf("XML body %s does not contain %s", body, want)
+	}
+
+	var resp erz.HTTPResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(resp.Error.Fields["email"]) + len(resp.Error.Fields["name"]); got != 2 {
+		t.Errorf("decoded %d violations, want 2: %+v", got, resp.Error.Fields)
+	}
+}
+
+func TestDefaultHTTPErrorHandlerRequestIDPrecedence(t *testing.T) {
+	erz.SetRequestIDGenerator(func() string { return "generated" })
+	defer erz.SetRequestIDGenerator(nil)
+
+	tests := []struct {
+		name       string
+		header     string
+		err        error
+		wantBody   string
+		wantHeader string
+	}{
+		{name: "header", header: "from-header", err: erz.NotFound("user").WithRequestID("from-error"), wantBody: "from-header"},
+		{name: "error", err: erz.NotFound("user").WithRequestID("from-error"), wantBody: "from-error"},
+		{name: "generated", err: erz.NotFound("user"), wantBody: "generated", wantHeader: "generated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				if tt.header != "" {
+					r.Header.Set(erz.HeaderRequestID, tt.header)
+				}
+				rec := httptest.NewRecorder()
+				erz.DefaultHTTPErrorHandler(rec, r, tt.err, &erz.HTTPOptions{GenerateRequestID: true})
+
+				var resp erz.HTTPResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.RequestID != tt.wantBody {
+					t.Errorf("body request_id %q, want %q", resp.RequestID, tt.wantBody)
+				}
+				if got := rec.Header().Get(erz.HeaderRequestID); got != tt.wantHeader {
+					t.Errorf("%s header %q, want %q", erz.HeaderRequestID, got, tt.wantHeader)
+				}
+			},
+		)
+	}
+}
+
+func TestPaginationJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *erz.HTTPResponse
+		want     string
+	}{
+		{
+			name:     "offset",
+			response: erz.CreateSuccessResponse(nil, nil).WithPagination(2, 10, 25),
+			want:     `{"page":2,"per_page":10,"total":25,"total_pages":3,"has_next":true,"has_prev":true}`,
+		},
+		{
+			name:     "cursor",
+			response: erz.CreateSuccessResponse(nil, nil).WithCursorPagination("next", "prev", true, true),
+			want:     `{"next_cursor":"next","prev_cursor":"prev","has_next":true,"has_prev":true}`,
+		},
+		{
+			name:     "cursor replaces offset",
+			response: erz.CreateSuccessResponse(nil, nil).WithPagination(1, 10, 5).WithCursorPagination("next", "", true, false),
+			want:     `{"next_cursor":"next","has_next":true,"has_prev":false}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := json.Marshal(tt.response.Meta.Pagination)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("got  %s\nwant %s", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestWriteHTTPResponseWarnings(t *testing.T) {
+	resp := erz.CreateSuccessResponse(map[string]string{"id": "1"}, nil).
+		AddWarning("UNKNOWN_FIELD", "field 'colour' was ignored")
+
+	rec := httptest.NewRecorder()
+	if err := erz.WriteHTTPResponse(rec, resp, nil); err != nil {
+		t.Fatal(err)
+	}
+	assertJSONResponse(t, rec, http.StatusOK)
+
+	var got erz.HTTPResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []erz.Warning{{Code: "UNKNOWN_FIELD", Message: "field 'colour' was ignored"}}
+	if !got.Success || !slices.Equal(got.Warnings, want) {
+		t.Errorf("got success %v, warnings %+v; want warnings %+v", got.Success, got.Warnings, want)
+	}
+}
@@ -0,0 +1,111 @@
+package erz
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type MessageResolver interface {
+	Resolve(code ErrorCode, lang string) (string, bool)
+}
+
+// KeyResolver can additionally be implemented by a MessageResolver to
+// translate message keys set with WithMessageKey. Validation error messages
+// are also passed through it, so they can be written as keys.
+type KeyResolver interface {
+	ResolveKey(key, lang string) (string, bool)
+}
+
+var (
+	resolverMu      sync.RWMutex
+	messageResolver MessageResolver
+)
+
+func SetMessageResolver(resolver MessageResolver) {
+	resolverMu.Lock()
+	defer resolverMu.Unlock()
+
+	messageResolver = resolver
+}
+
+func getMessageResolver() MessageResolver {
+	resolverMu.RLock()
+	defer resolverMu.RUnlock()
+
+	return messageResolver
+}
+
+// PublicErrorLang returns the public message localized for lang. The message
+// key is resolved first, then an explicitly set public message is used as is,
+// then the code is resolved, and finally the English default is returned.
+func (e *Er) PublicErrorLang(lang string) string {
+	resolver := getMessageResolver()
+	if resolver == nil || lang == "" {
+		return e.PublicError()
+	}
+
+	if keyResolver, ok := resolver.(KeyResolver); ok && e.messageKey != "" {
+		if msg, ok := keyResolver.ResolveKey(e.messageKey, lang); ok {
+			return msg
+		}
+	}
+
+	if e.publicMessage == "" {
+		if msg, ok := resolver.Resolve(e.errCode, lang); ok {
+			return msg
+		}
+	}
+
+	return e.PublicError()
+}
+
+// LocalizeValidationErrors returns a copy of errs with each message resolved
+// as a key for lang. Messages the resolver doesn't know are kept unchanged.
+func LocalizeValidationErrors(errs []ValidationError, lang string) []ValidationError {
+	if len(errs) == 0 || lang == "" {
+		return errs
+	}
+
+	keyResolver, ok := getMessageResolver().(KeyResolver)
+	if !ok {
+		return errs
+	}
+
+	localized := make([]ValidationError, len(errs))
+	copy(localized, errs)
+	for i := range localized {
+		if msg, ok := keyResolver.ResolveKey(localized[i].Message, lang); ok {
+			localized[i].Message = msg
+		}
+	}
+
+	return localized
+}
+
+// ParseAcceptLanguage returns the language tag with the highest quality value
+// from an Accept-Language header, or an empty string if there is none.
+func ParseAcceptLanguage(header string) string {
+	best, bestQuality := "", -1.0
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = tag, quality
+		}
+	}
+
+	return best
+}
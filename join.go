@@ -0,0 +1,85 @@
+package erz
+
+import (
+	"strings"
+)
+
+// codePrecedence orders codes from most to least severe. Join and other
+// aggregations pick the most severe code among their inputs; codes that are
+// not listed rank below all listed ones.
+var codePrecedence = []ErrorCode{
+	CodeInternal,
+	CodeUnknown,
+	CodeBadGateway,
+	CodeUnavailable,
+	CodeGatewayTimeout,
+	CodeTimeout,
+	CodeNotImplemented,
+	CodeResourceExhausted,
+	CodeCanceled,
+	CodeUnauthenticated,
+	CodePermissionDenied,
+	CodeConflict,
+	CodeAlreadyExists,
+	CodeValidation,
+	CodeInvalidInput,
+	CodeMethodNotAllowed,
+	CodeGone,
+	CodeNotFound,
+}
+
+func codeSeverity(code ErrorCode) int {
+	for i, c := range codePrecedence {
+		if c == code {
+			return len(codePrecedence) - i
+		}
+	}
+	return 0
+}
+
+func mostSevereCode(current, candidate ErrorCode) ErrorCode {
+	if current == "" || codeSeverity(candidate) > codeSeverity(current) {
+		return candidate
+	}
+	return current
+}
+
+// Join combines errs into a single Error that wraps all non-nil inputs. The
+// code is the most severe one according to codePrecedence, and the message is
+// the concatenation of the input messages. It returns nil if all inputs are nil.
+func Join(errs ...error) Error {
+	joined := join(errs)
+	if joined == nil {
+		return nil
+	}
+	return observe(joined)
+}
+
+func join(errs []error) *Er {
+	var (
+		code     ErrorCode
+		messages []string
+		wrapped  []error
+	)
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		code = mostSevereCode(code, CodeOf(err))
+		messages = append(messages, err.Error())
+		wrapped = append(wrapped, err)
+	}
+
+	if len(wrapped) == 0 {
+		return nil
+	}
+
+	return &Er{
+		errCode:   code,
+		message:   strings.Join(messages, "; "),
+		wrapped:   wrapped,
+		lazyStack: sampledStack(3),
+	}
+}
@@ -0,0 +1,66 @@
+package erz_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/intezya/erz"
+	"testing"
+)
+
+func TestJoinPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []error
+		want erz.ErrorCode
+	}{
+		{"internal over unavailable", []error{erz.New(erz.CodeUnavailable, "db is unavailable"), erz.Internal("boom")}, erz.CodeInternal},
+		{"unavailable over validation", []error{erz.Validation("bad"), erz.New(erz.CodeUnavailable, "db is unavailable")}, erz.CodeUnavailable},
+		{"validation over not found", []error{erz.NotFound("user"), erz.Validation("bad")}, erz.CodeValidation},
+		{"conflict over already exists", []error{erz.AlreadyExists("user"), erz.Conflict("order")}, erz.CodeConflict},
+		{"permission denied over not found", []error{erz.NotFound("user"), erz.PermissionDenied("admin")}, erz.CodePermissionDenied},
+		{"single error", []error{erz.NotFound("user")}, erz.CodeNotFound},
+		{"wrapped erz error", []error{erz.NotFound("user"), fmt.Errorf("sync: %w", erz.New(erz.CodeUnavailable, "db is unavailable"))}, erz.CodeUnavailable},
+		{"deadline counts as timeout", []error{erz.NotFound("user"), context.DeadlineExceeded}, erz.CodeTimeout},
+		{"plain error is unknown", []error{erz.Conflict("order"), errors.New("boom")}, erz.CodeUnknown},
+		{"listed code over unlisted", []error{erz.New("CUSTOM", "custom"), erz.NotFound("user")}, erz.CodeNotFound},
+		{"nils are skipped", []error{nil, erz.Gone("file"), nil}, erz.CodeGone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := erz.Join(tt.errs...)
+			if err == nil {
+				t.Fatal("Join returned nil")
+			}
+			if err.Code() != tt.want {
+				t.Errorf("code %s, want %s", err.Code(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinOrderIndependent(t *testing.T) {
+	a, b := erz.NotFound("user"), erz.Internal("boom")
+	if got, want := erz.Join(a, b).Code(), erz.Join(b, a).Code(); got != want {
+		t.Errorf("Join(a, b) is %s but Join(b, a) is %s", got, want)
+	}
+}
+
+func TestJoinWrapsAll(t *testing.T) {
+	first, second := erz.NotFound("user"), errors.New("boom")
+	err := erz.Join(first, nil, second)
+
+	if !errors.Is(err, first) || !errors.Is(err, second) {
+		t.Error("errors.Is does not match every input")
+	}
+	if got := len(err.Unwrap()); got != 2 {
+		t.Errorf("%d wrapped errors, want 2", got)
+	}
+	if got, want := err.Error(), "user not found; boom"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if erz.Join(nil, nil) != nil {
+		t.Error("Join of nils is not nil")
+	}
+}
@@ -4,6 +4,19 @@ import (
 	"fmt"
 )
 
+// NewPublic creates an error with both its internal message and the message
+// shown to clients, so PublicError never falls back to the code's default.
+func NewPublic(code ErrorCode, internal, public string) Error {
+	return observe(
+		&Er{
+			errCode:       code,
+			message:       internal,
+			publicMessage: public,
+			lazyStack:     sampledStack(2),
+		},
+	)
+}
+
 func NotFound(resource string) Error {
 	return New(CodeNotFound, fmt.Sprintf("%s not found", resource))
 }
@@ -12,10 +25,19 @@ func InvalidInput(field string) Error {
 	return New(CodeInvalidInput, fmt.Sprintf("invalid input: %s", field))
 }
 
+// Gone reports a resource that existed but was permanently deleted.
+func Gone(resource string) Error {
+	return New(CodeGone, fmt.Sprintf("%s is gone", resource))
+}
+
 func AlreadyExists(resource string) Error {
 	return New(CodeAlreadyExists, fmt.Sprintf("%s already exists", resource))
 }
 
+func Conflict(resource string) Error {
+	return New(CodeConflict, fmt.Sprintf("conflict: %s", resource))
+}
+
 func PermissionDenied(action string) Error {
 	return New(CodePermissionDenied, fmt.Sprintf("permission denied: %s", action))
 }
@@ -32,22 +54,48 @@ func InternalWithCause(message string, cause error) Error {
 	return Wrap(cause, CodeInternal, message)
 }
 
+func Canceled(message string) Error {
+	return New(CodeCanceled, message)
+}
+
+func BadGateway(message string) Error {
+	return New(CodeBadGateway, message)
+}
+
+func GatewayTimeout(message string) Error {
+	return New(CodeGatewayTimeout, message)
+}
+
+func NotImplemented(feature string) Error {
+	return New(CodeNotImplemented, fmt.Sprintf("not implemented: %s", feature))
+}
+
+func MethodNotAllowed(method string) Error {
+	return New(CodeMethodNotAllowed, fmt.Sprintf("method not allowed: %s", method))
+}
+
 func Validation(message string) Error {
 	return New(CodeValidation, message)
 }
 
 func ValidationSingle(field, message string, value any) Error {
-	return &Er{
-		errCode: CodeValidation,
-		message: fmt.Sprintf("validation failed for field: %s", field),
-		validationErrors: []ValidationError{
-			{
-				Field:   field,
-				Message: message,
-				Value:   value,
+	return observe(
+		&Er{
+			errCode: CodeValidation,
+			message: fmt.Sprintf("validation failed for field: %s", field),
+			validationErrors: []ValidationError{
+				{
+					Field:   field,
+					Message: message,
+					Value:   value,
+				},
 			},
 		},
-	}
+	)
+}
+
+func FieldError(field, message string, value any) Error {
+	return ValidationSingle(field, message, value)
 }
 
 func DatabaseError(operation string, err error) Error {
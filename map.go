@@ -0,0 +1,65 @@
+package erz
+
+import "fmt"
+
+// ToMap returns the error as a map suitable for structured loggers that take
+// map[string]any fields. Empty fields are omitted and nested values only use
+// maps, slices and scalars so the result can be JSON encoded.
+func (e *Er) ToMap() map[string]any {
+	if e == nil {
+		return errNilPassed().ToMap()
+	}
+
+	fields := map[string]any{
+		"code":    string(e.errCode),
+		"message": e.message,
+	}
+
+	if e.reason != "" {
+		fields["reason"] = e.reason
+	}
+
+	if e.detail != "" {
+		fields["detail"] = e.detail
+	}
+
+	if e.hint != "" {
+		fields["hint"] = e.hint
+	}
+
+	if e.operation != "" {
+		fields["operation"] = e.operation
+	}
+
+	if e.publicMessage != "" {
+		fields["public_message"] = e.publicMessage
+	}
+
+	if len(e.validationErrors) > 0 {
+		validationErrors := make([]map[string]any, 0, len(e.validationErrors))
+		for _, ve := range sanitizeValidationErrors(e.validationErrors) {
+			entry := map[string]any{
+				"field":   ve.Field,
+				"message": ve.Message,
+			}
+			if ve.Code != "" {
+				entry["code"] = ve.Code
+			}
+			if ve.Value != nil {
+				entry["value"] = ve.Value
+			}
+			validationErrors = append(validationErrors, entry)
+		}
+		fields["validation_errors"] = validationErrors
+	}
+
+	if stackTrace := e.GetStackTrace(); len(stackTrace) > 0 {
+		frames := make([]string, 0, len(stackTrace))
+		for _, frame := range stackTrace {
+			frames = append(frames, fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function))
+		}
+		fields["stack_trace"] = frames
+	}
+
+	return fields
+}
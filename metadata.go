@@ -0,0 +1,55 @@
+package erz
+
+// Metadata walks the chain of err and returns the value stored under key by
+// WithMetadata on the first erz error that has it. The second result is false
+// if no error carries the key or the value is not of type T.
+func Metadata[T any](err error, key string) (T, bool) {
+	var (
+		result T
+		found  bool
+	)
+
+	walkChain(
+		err, func(err error) bool {
+			erzErr, ok := err.(Error)
+			if !ok {
+				return false
+			}
+
+			value, exists := erzErr.GetMetadata()[key]
+			if !exists {
+				return false
+			}
+
+			result, found = value.(T)
+			return true
+		},
+	)
+
+	return result, found
+}
+
+// walkChain visits err and everything it wraps, depth first, until visit
+// returns true.
+func walkChain(err error, visit func(error) bool) bool {
+	if err == nil {
+		return false
+	}
+
+	if visit(err) {
+		return true
+	}
+
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkChain(u.Unwrap(), visit)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range u.Unwrap() {
+			if walkChain(wrapped, visit) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
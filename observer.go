@@ -0,0 +1,75 @@
+package erz
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type ErrorObserver func(Error)
+
+var (
+	errorObserver atomic.Pointer[ErrorObserver]
+
+	addedObserversMu sync.Mutex
+	addedObservers   atomic.Pointer[[]*ErrorObserver]
+)
+
+// SetErrorObserver registers a hook that is called with every error created
+// by the package constructors (New, Wrap, Join and the helpers in known.go).
+// The hook runs synchronously on the goroutine creating the error, so it must
+// be fast and safe for concurrent use. Passing nil removes the hook. Observers
+// added with AddErrorObserver are not affected.
+func SetErrorObserver(observer ErrorObserver) {
+	if observer == nil {
+		errorObserver.Store(nil)
+		return
+	}
+	errorObserver.Store(&observer)
+}
+
+// AddErrorObserver registers an additional hook with the same contract as
+// SetErrorObserver, without replacing the hooks already registered. The
+// returned function removes it.
+func AddErrorObserver(observer ErrorObserver) (remove func()) {
+	handle := &observer
+
+	addedObserversMu.Lock()
+	defer addedObserversMu.Unlock()
+
+	var observers []*ErrorObserver
+	if current := addedObservers.Load(); current != nil {
+		observers = append(observers, *current...)
+	}
+	observers = append(observers, handle)
+	addedObservers.Store(&observers)
+
+	return func() {
+		addedObserversMu.Lock()
+		defer addedObserversMu.Unlock()
+
+		current := addedObservers.Load()
+		if current == nil {
+			return
+		}
+		remaining := make([]*ErrorObserver, 0, len(*current))
+		for _, o := range *current {
+			if o != handle {
+				remaining = append(remaining, o)
+			}
+		}
+		addedObservers.Store(&remaining)
+	}
+}
+
+func observe(err *Er) *Er {
+	err.statusCache = &statusCache{}
+	if observer := errorObserver.Load(); observer != nil {
+		(*observer)(err)
+	}
+	if observers := addedObservers.Load(); observers != nil {
+		for _, observer := range *observers {
+			(*observer)(err)
+		}
+	}
+	return err
+}
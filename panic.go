@@ -0,0 +1,43 @@
+package erz
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RecoverToError converts a value returned by recover into an Error. Errors
+// from this package are returned unchanged, other errors are wrapped as
+// CodeInternal and any other value is formatted into the message. When called
+// from the deferred function, the stack trace starts at the panic site.
+func RecoverToError(recovered any) Error {
+	if recovered == nil {
+		return nil
+	}
+
+	var err error
+
+	switch v := recovered.(type) {
+	case Error:
+		return v
+	case error:
+		err = v
+	case string:
+		err = errors.New(v)
+	default:
+		err = fmt.Errorf("panic recovered: %v", v)
+	}
+
+	return InternalWithCause("panic recovered", err).WithPanicStackTrace()
+}
+
+// FromPanic is RecoverToError under a name that reads naturally in a deferred
+// function with a named error result:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			err = erz.FromPanic(r)
+//		}
+//	}()
+func FromPanic(recovered any) Error {
+	return RecoverToError(recovered)
+}
@@ -0,0 +1,86 @@
+package erz_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/intezya/erz"
+	"google.golang.org/protobuf/encoding/prototext"
+	"strings"
+	"testing"
+)
+
+func internalError() erz.Error {
+	return erz.Wrap(errors.New("dial tcp db-primary.internal:5432"), erz.CodeInternal, "query on users table failed").
+		WithDetail("SELECT * FROM users WHERE id = 42").
+		WithStackTrace()
+}
+
+// internalStrings must never appear in redacted output. The name of
+// internalError shows up in its stack trace.
+var internalStrings = []string{
+	"db-primary.internal",
+	"users table",
+	"SELECT * FROM users",
+	"internalError",
+}
+
+func assertNoInternalStrings(t *testing.T, format string, out []byte) {
+	t.Helper()
+
+	for _, s := range internalStrings {
+		if strings.Contains(string(out), s) {
+			t.Errorf("%s output contains %q:\n%s", format, s, out)
+		}
+	}
+}
+
+func TestRedactedResponses(t *testing.T) {
+	err := internalError()
+
+	opts := erz.DefaultHTTPOptions()
+	opts.IncludeStackTrace = true
+
+	t.Run("unredacted", func(t *testing.T) {
+		out, _ := json.Marshal(err.ToHTTPResponse(opts))
+		for _, s := range internalStrings[1:] {
+			if !strings.Contains(string(out), s) {
+				t.Errorf("unredacted output lacks %q, so the test proves nothing:\n%s", s, out)
+			}
+		}
+	})
+
+	opts.Redact = true
+	resp := err.ToHTTPResponse(opts)
+
+	t.Run("json", func(t *testing.T) {
+		out, marshalErr := json.Marshal(resp)
+		if marshalErr != nil {
+			t.Fatal(marshalErr)
+		}
+		assertNoInternalStrings(t, "JSON", out)
+		if resp.Error.Message != err.PublicError() {
+			t.Errorf("message %q, want the public message %q", resp.Error.Message, err.PublicError())
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		out, marshalErr := xml.Marshal(resp)
+		if marshalErr != nil {
+			t.Fatal(marshalErr)
+		}
+		assertNoInternalStrings(t, "XML", out)
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		st := err.Redacted().GRPCStatus()
+		out, marshalErr := prototext.Marshal(st.Proto())
+		if marshalErr != nil {
+			t.Fatal(marshalErr)
+		}
+		assertNoInternalStrings(t, "gRPC", out)
+		if st.Message() != err.PublicError() {
+			t.Errorf("message %q, want the public message %q", st.Message(), err.PublicError())
+		}
+	})
+}
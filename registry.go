@@ -0,0 +1,152 @@
+package erz
+
+import (
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type CodeMapping struct {
+	HTTPStatus    int
+	GRPCCode      codes.Code
+	PublicMessage string
+}
+
+var (
+	registryMu          sync.RWMutex
+	registeredCodes     = make(map[ErrorCode]CodeMapping)
+	httpStatusOverrides = make(map[ErrorCode]int)
+	grpcCodeOverrides   = make(map[ErrorCode]codes.Code)
+)
+
+// RegisterCode adds a custom error code with its transport mappings. Built-in
+// codes always keep their own mappings.
+func RegisterCode(code ErrorCode, mapping CodeMapping) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registeredCodes[code] = mapping
+}
+
+// SetHTTPStatusForCode overrides the HTTP status of any code, including the
+// built-in ones, e.g. to answer CodeValidation with 422. FromHTTPStatus maps
+// the status back to the code. Passing 0 removes the override.
+func SetHTTPStatusForCode(code ErrorCode, status int) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if status == 0 {
+		delete(httpStatusOverrides, code)
+		return
+	}
+	httpStatusOverrides[code] = status
+}
+
+// SetGRPCCodeForCode overrides the gRPC code of any code, including the
+// built-in ones. Passing codes.OK removes the override. Call it during
+// startup, as gRPC statuses are cached per error.
+func SetGRPCCodeForCode(code ErrorCode, grpcCode codes.Code) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if grpcCode == codes.OK {
+		delete(grpcCodeOverrides, code)
+		return
+	}
+	grpcCodeOverrides[code] = grpcCode
+}
+
+func httpStatusOverride(code ErrorCode) (int, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	status, ok := httpStatusOverrides[code]
+	return status, ok
+}
+
+func grpcCodeOverride(code ErrorCode) (codes.Code, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	grpcCode, ok := grpcCodeOverrides[code]
+	return grpcCode, ok
+}
+
+// codeForHTTPStatusOverride returns the code whose status was overridden to
+// status. If several were, the smallest code wins so the result is stable.
+func codeForHTTPStatusOverride(status int) (ErrorCode, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	var found ErrorCode
+	for code, overridden := range httpStatusOverrides {
+		if overridden == status && (found == "" || code < found) {
+			found = code
+		}
+	}
+	return found, found != ""
+}
+
+func registeredCode(code ErrorCode) (CodeMapping, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	mapping, ok := registeredCodes[code]
+	return mapping, ok
+}
+
+func isKnownCode(code ErrorCode) bool {
+	for _, builtin := range builtinCodes {
+		if code == builtin {
+			return true
+		}
+	}
+
+	_, ok := registeredCode(code)
+	return ok
+}
+
+func registeredCodeList() []ErrorCode {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	list := make([]ErrorCode, 0, len(registeredCodes))
+	for code := range registeredCodes {
+		list = append(list, code)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+
+	return list
+}
+
+// ValidateMappings checks that every built-in and registered code has an HTTP
+// status, a gRPC code and a public message. It is meant to be called from
+// init or tests after RegisterCode.
+func ValidateMappings() error {
+	var gaps []string
+
+	allCodes := append(append([]ErrorCode{}, builtinCodes...), registeredCodeList()...)
+	for _, code := range allCodes {
+		var missing []string
+		if _, ok := httpStatusForCode(code); !ok {
+			missing = append(missing, "HTTP status")
+		}
+		if _, ok := grpcCodeForCode(code); !ok {
+			missing = append(missing, "gRPC code")
+		}
+		if _, ok := publicMessageForCode(code); !ok {
+			missing = append(missing, "public message")
+		}
+
+		if len(missing) > 0 {
+			gaps = append(gaps, fmt.Sprintf("%s: missing %s", code, strings.Join(missing, ", ")))
+		}
+	}
+
+	if len(gaps) > 0 {
+		return fmt.Errorf("incomplete error code mappings: %s", strings.Join(gaps, "; "))
+	}
+	return nil
+}
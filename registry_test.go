@@ -0,0 +1,98 @@
+package erz_test
+
+import (
+	"github.com/intezya/erz"
+	"google.golang.org/grpc/codes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetHTTPStatusForCode(t *testing.T) {
+	if got := erz.Validation("invalid").HTTPStatus(); got != http.StatusBadRequest {
+		t.Fatalf("default status %d, want %d", got, http.StatusBadRequest)
+	}
+
+	erz.SetHTTPStatusForCode(erz.CodeValidation, http.StatusUnprocessableEntity)
+	defer erz.SetHTTPStatusForCode(erz.CodeValidation, 0)
+
+	err := erz.Validation("invalid").WithValidationError("email", "is required", nil)
+	if got := err.HTTPStatus(); got != http.StatusUnprocessableEntity {
+		t.Errorf("status %d, want %d", got, http.StatusUnprocessableEntity)
+	}
+
+	rec := httptest.NewRecorder()
+	if writeErr := erz.WriteHTTPError(rec, err, nil); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("written status %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+
+	if got := erz.FromHTTPStatus(http.StatusUnprocessableEntity, "invalid").Code(); got != erz.CodeValidation {
+		t.Errorf("FromHTTPStatus(422) code %s, want %s", got, erz.CodeValidation)
+	}
+	if got := erz.NotFound("user").HTTPStatus(); got != http.StatusNotFound {
+		t.Errorf("status of a code without override %d, want %d", got, http.StatusNotFound)
+	}
+	if got := erz.InvalidInput("email").HTTPStatus(); got != http.StatusBadRequest {
+		t.Errorf("status of CodeInvalidInput %d, want %d", got, http.StatusBadRequest)
+	}
+
+	erz.SetHTTPStatusForCode(erz.CodeValidation, 0)
+	if got := erz.Validation("invalid").HTTPStatus(); got != http.StatusBadRequest {
+		t.Errorf("status after removing the override %d, want %d", got, http.StatusBadRequest)
+	}
+}
+
+func TestSetGRPCCodeForCode(t *testing.T) {
+	erz.SetGRPCCodeForCode(erz.CodeConflict, codes.FailedPrecondition)
+	defer erz.SetGRPCCodeForCode(erz.CodeConflict, codes.OK)
+
+	if got := erz.Conflict("order").GRPCStatus().Code(); got != codes.FailedPrecondition {
+		t.Errorf("gRPC code %s, want %s", got, codes.FailedPrecondition)
+	}
+	if got := erz.NotFound("order").GRPCStatus().Code(); got != codes.NotFound {
+		t.Errorf("gRPC code of a code without override %s, want %s", got, codes.NotFound)
+	}
+}
+
+func TestRegisteredCodeRoundTrip(t *testing.T) {
+	const codePaymentRequired erz.ErrorCode = "PAYMENT_REQUIRED"
+	erz.RegisterCode(codePaymentRequired, erz.CodeMapping{HTTPStatus: http.StatusPaymentRequired, GRPCCode: codes.FailedPrecondition})
+
+	original := erz.New(codePaymentRequired, "card declined").WithDetail("issuer declined the charge")
+
+	t.Run("http", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		if err := erz.WriteHTTPError(rec, original, nil); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusPaymentRequired {
+			t.Errorf("status %d, want %d", rec.Code, http.StatusPaymentRequired)
+		}
+
+		got, err := erz.FromHTTPResponse(rec.Result())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Code() != codePaymentRequired || got.GetMessage() != "card declined" {
+			t.Errorf("got code %s, message %q; want %s, %q", got.Code(), got.GetMessage(), codePaymentRequired, "card declined")
+		}
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		st := original.GRPCStatus()
+		if st.Code() != codes.FailedPrecondition {
+			t.Errorf("gRPC code %s, want %s", st.Code(), codes.FailedPrecondition)
+		}
+
+		got := erz.FromGRPCStatusWithDetails(st)
+		if got.Code() != codePaymentRequired || got.GetDetail() != original.GetDetail() {
+			t.Errorf("got code %s, detail %q; want %s, %q", got.Code(), got.GetDetail(), codePaymentRequired, original.GetDetail())
+		}
+		if got.HTTPStatus() != http.StatusPaymentRequired {
+			t.Errorf("HTTP status after the round trip %d, want %d", got.HTTPStatus(), http.StatusPaymentRequired)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package erz
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync/atomic"
+)
+
+var requestIDGenerator atomic.Pointer[func() string]
+
+// SetRequestIDGenerator replaces the function used to create request IDs when
+// HTTPOptions.GenerateRequestID is set. Passing nil restores the default
+// random UUID generator.
+func SetRequestIDGenerator(generate func() string) {
+	if generate == nil {
+		requestIDGenerator.Store(nil)
+		return
+	}
+	requestIDGenerator.Store(&generate)
+}
+
+func generateRequestID() string {
+	if g := requestIDGenerator.Load(); g != nil {
+		return (*g)()
+	}
+	return newUUID()
+}
+
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,170 @@
+package erz
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+type ValidationValueSanitizer func(field string, value any) any
+
+type ValidationFieldTransformer func(field string) string
+
+const defaultValidationValueMaxLen = 256
+
+var (
+	validationValueSanitizer   atomic.Pointer[ValidationValueSanitizer]
+	validationValueMaxLen      atomic.Int64
+	validationFieldTransformer atomic.Pointer[ValidationFieldTransformer]
+)
+
+func init() {
+	validationValueMaxLen.Store(defaultValidationValueMaxLen)
+}
+
+// SetValidationValueMaxLen limits the size of validation error values in
+// responses. Longer strings are truncated to n bytes, including a trailing
+// "...", and other values whose printed form is longer are replaced by a
+// placeholder naming their type. The default is 256; n <= 0 disables the
+// limit.
+func SetValidationValueMaxLen(n int) {
+	validationValueMaxLen.Store(int64(n))
+}
+
+// SetValidationValueSanitizer registers a hook that rewrites validation error
+// values before they leave the process in HTTP responses, MarshalJSON and
+// ToMap, e.g. to mask passwords or tokens. The length limit set with
+// SetValidationValueMaxLen is applied to its result. gRPC statuses never carry values.
+// Passing nil restores the identity behavior.
+func SetValidationValueSanitizer(sanitizer ValidationValueSanitizer) {
+	if sanitizer == nil {
+		validationValueSanitizer.Store(nil)
+		return
+	}
+	validationValueSanitizer.Store(&sanitizer)
+}
+
+// SetValidationFieldTransformer registers a function that renames validation
+// error fields in HTTP responses, MarshalJSON, ToMap and gRPC BadRequest
+// details, e.g. SnakeCaseField to turn "UserName" into "user_name". The
+// sanitizer sees the transformed names. Passing nil keeps fields unchanged.
+// Set it during startup: GRPCStatus is memoized per error, so an error whose
+// status was already built keeps the field names of that time.
+func SetValidationFieldTransformer(transformer ValidationFieldTransformer) {
+	if transformer == nil {
+		validationFieldTransformer.Store(nil)
+		return
+	}
+	validationFieldTransformer.Store(&transformer)
+}
+
+func transformValidationField(field string) string {
+	if transformer := validationFieldTransformer.Load(); transformer != nil {
+		return (*transformer)(field)
+	}
+	return field
+}
+
+func sanitizeValidationErrors(errs []ValidationError) []ValidationError {
+	sanitizer := validationValueSanitizer.Load()
+	maxLen := int(validationValueMaxLen.Load())
+	transformer := validationFieldTransformer.Load()
+	if (sanitizer == nil && maxLen <= 0 && transformer == nil) || len(errs) == 0 {
+		return errs
+	}
+
+	sanitized := make([]ValidationError, len(errs))
+	copy(sanitized, errs)
+	for i := range sanitized {
+		if transformer != nil {
+			sanitized[i].Field = (*transformer)(sanitized[i].Field)
+		}
+		if sanitized[i].Value == nil {
+			continue
+		}
+		if sanitizer != nil {
+			sanitized[i].Value = (*sanitizer)(sanitized[i].Field, sanitized[i].Value)
+		}
+		if maxLen > 0 {
+			sanitized[i].Value = limitValue(sanitized[i].Value, maxLen)
+		}
+	}
+
+	return sanitized
+}
+
+const truncationSuffix = "..."
+
+func limitValue(value any, maxLen int) any {
+	switch v := value.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return value
+	case string:
+		if len(v) <= maxLen {
+			return v
+		}
+		// The suffix counts towards the limit, unless the limit is too small
+		// to hold anything else.
+		suffix := truncationSuffix
+		if maxLen <= len(suffix) {
+			suffix = ""
+		}
+		// Cut on a rune boundary so the result stays valid UTF-8.
+		cut := maxLen - len(suffix)
+		for cut > 0 && !utf8.RuneStart(v[cut]) {
+			cut--
+		}
+		return v[:cut] + suffix
+	}
+
+	// Check a lower bound first, so large values are never formatted.
+	if minPrintedLen(reflect.ValueOf(value), maxLen, true) > maxLen || len(fmt.Sprint(value)) > maxLen {
+		return fmt.Sprintf("<%T>", value)
+	}
+	return value
+}
+
+// minPrintedLen returns a lower bound of the length fmt.Sprint prints for v. It
+// stops counting once limit is exceeded. Every element counts at least one
+// byte, so cyclic values end as well.
+func minPrintedLen(v reflect.Value, limit int, top bool) int {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return len("<nil>")
+	case reflect.String:
+		return v.Len()
+	case reflect.Interface:
+		if v.IsNil() {
+			return len("<nil>")
+		}
+		return minPrintedLen(v.Elem(), limit, top)
+	case reflect.Pointer:
+		// fmt only follows the outermost pointer, nested ones print as addresses.
+		if v.IsNil() || !top {
+			return 1
+		}
+		return 1 + minPrintedLen(v.Elem(), limit-1, false)
+	case reflect.Slice, reflect.Array:
+		n := v.Len()
+		for i := 0; i < v.Len() && n <= limit; i++ {
+			n += minPrintedLen(v.Index(i), limit-n, false)
+		}
+		return n
+	case reflect.Map:
+		n := v.Len()
+		for iter := v.MapRange(); n <= limit && iter.Next(); {
+			n += minPrintedLen(iter.Key(), limit-n, false)
+			n += minPrintedLen(iter.Value(), limit-n, false)
+		}
+		return n
+	case reflect.Struct:
+		n := 2
+		for i := 0; i < v.NumField() && n <= limit; i++ {
+			n += minPrintedLen(v.Field(i), limit-n, false)
+		}
+		return n
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,117 @@
+package erz_test
+
+import (
+	"encoding/json"
+	"github.com/intezya/erz"
+	"google.golang.org/protobuf/encoding/prototext"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+type upload struct {
+	Name string
+	Data []byte
+}
+
+func limitedValue(t *testing.T, value any) any {
+	t.Helper()
+
+	resp := erz.ValidationSingle("file", "is invalid", value).ToHTTPResponse(nil)
+	return resp.Error.ValidationErrors[0].Value
+}
+
+func TestValidationValueMaxLen(t *testing.T) {
+	erz.SetValidationValueMaxLen(16)
+	defer erz.SetValidationValueMaxLen(256)
+
+	tests := []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{name: "short string", value: "short", want: "short"},
+		{name: "exact string", value: strings.Repeat("a", 16), want: strings.Repeat("a", 16)},
+		{name: "long string", value: strings.Repeat("a", 100), want: strings.Repeat("a", 13) + "..."},
+		{name: "multi-byte string", value: strings.Repeat("é", 20), want: strings.Repeat("é", 6) + "..."},
+		{name: "number", value: 1234567890123456789, want: 1234567890123456789},
+		{name: "small struct", value: upload{Name: "a"}, want: upload{Name: "a"}},
+		{name: "big struct", value: upload{Name: "a", Data: make([]byte, 1024)}, want: "<erz_test.upload>"},
+		{name: "big pointer", value: &upload{Name: strings.Repeat("a", 100)}, want: "<*erz_test.upload>"},
+		{name: "big map", value: map[string]string{"key": strings.Repeat("a", 100)}, want: "<map[string]string>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := limitedValue(t, tt.value)
+				if _, isString := tt.value.(string); isString {
+					if s := got.(string); len(s) > 16 || !utf8.ValidString(s) {
+						t.Errorf("value %q exceeds the limit or is invalid UTF-8", s)
+					}
+				}
+				if !erz.Equal(erz.ValidationSingle("file", "is invalid", got), erz.ValidationSingle("file", "is invalid", tt.want)) {
+					t.Errorf("value %#v, want %#v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestValidationValueMaxLenSkipsFormattingLargeValues(t *testing.T) {
+	erz.SetValidationValueMaxLen(16)
+	defer erz.SetValidationValueMaxLen(256)
+
+	// Printing this slice would take seconds and hundreds of megabytes.
+	huge := make([]int, 50_000_000)
+	if got := limitedValue(t, huge); got != "<[]int>" {
+		t.Errorf("value %v, want <[]int>", got)
+	}
+}
+
+func TestValidationValueSanitizerMasksPasswords(t *testing.T) {
+	erz.SetValidationValueSanitizer(
+		func(field string, value any) any {
+			if strings.Contains(strings.ToLower(field), "password") {
+				return "***"
+			}
+			return value
+		},
+	)
+	defer erz.SetValidationValueSanitizer(nil)
+
+	const secret = "hunter2-s3cret"
+	err := erz.Validation("invalid signup").
+		WithValidationError("user.password", "is too weak", secret).
+		WithValidationError("user.name", "is too short", "al")
+
+	t.Run("http", func(t *testing.T) {
+		out, marshalErr := json.Marshal(err.ToHTTPResponse(nil))
+		if marshalErr != nil {
+			t.Fatal(marshalErr)
+		}
+		if strings.Contains(string(out), secret) {
+			t.Errorf("HTTP response leaks the password:\n%s", out)
+		}
+		values := map[string]any{}
+		for _, ve := range err.ToHTTPResponse(nil).Error.ValidationErrors {
+			values[ve.Field] = ve.Value
+		}
+		if values["user.password"] != "***" || values["user.name"] != "al" {
+			t.Errorf("values %v, want the password masked and the name kept", values)
+		}
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		out, marshalErr := prototext.Marshal(err.GRPCStatus().Proto())
+		if marshalErr != nil {
+			t.Fatal(marshalErr)
+		}
+		if strings.Contains(string(out), secret) {
+			t.Errorf("gRPC status leaks the password:\n%s", out)
+		}
+		if !strings.Contains(string(out), "user.password") {
+			t.Errorf("gRPC status lacks the password field violation:\n%s", out)
+		}
+	})
+}
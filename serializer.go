@@ -0,0 +1,255 @@
+package erz
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeXML  = "application/xml"
+)
+
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+// Serializer encodes response envelopes. Set it on HTTPOptions or register it
+// for a media type so DefaultHTTPErrorHandler can pick it from the Accept
+// header.
+type Serializer interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	NewEncoder(w io.Writer) Encoder
+}
+
+type JSONSerializer struct{}
+
+func (JSONSerializer) ContentType() string {
+	return MediaTypeJSON
+}
+
+func (JSONSerializer) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONSerializer) NewEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+// XMLSerializer encodes envelopes as XML. Metadata, response headers and the
+// fields of EnvelopeV2 are maps, which encoding/xml cannot represent, and are
+// left out, so XML clients should use EnvelopeV1. Validation error values that
+// XML cannot encode are sent as their JSON text. Response data has to be
+// XML-encodable; otherwise the write functions fall back to JSON.
+type XMLSerializer struct{}
+
+func (XMLSerializer) ContentType() string {
+	return MediaTypeXML
+}
+
+func (XMLSerializer) Marshal(v interface{}) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+func (XMLSerializer) NewEncoder(w io.Writer) Encoder {
+	return xml.NewEncoder(w)
+}
+
+var (
+	serializersMu sync.RWMutex
+	serializers   = map[string]Serializer{MediaTypeJSON: JSONSerializer{}}
+)
+
+// RegisterSerializer makes s available for content negotiation under
+// mediaType, e.g. "application/xml".
+func RegisterSerializer(mediaType string, s Serializer) {
+	serializersMu.Lock()
+	defer serializersMu.Unlock()
+
+	serializers[strings.ToLower(mediaType)] = s
+}
+
+// NegotiateSerializer picks the registered serializer with the highest quality
+// in an Accept header. Media types with q=0 are not acceptable. It falls back
+// to JSON when nothing registered matches.
+func NegotiateSerializer(accept string) (string, Serializer) {
+	serializersMu.RLock()
+	defer serializersMu.RUnlock()
+
+	best, bestQuality := MediaTypeJSON, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if _, ok := serializers[mediaType]; !ok {
+			continue
+		}
+
+		if quality := acceptQuality(params[1:]); quality > bestQuality {
+			best, bestQuality = mediaType, quality
+		}
+	}
+
+	return best, serializers[best]
+}
+
+// acceptQuality returns the q parameter among the parameters of an Accept
+// entry, 1 if there is none.
+func acceptQuality(params []string) float64 {
+	for _, param := range params {
+		name, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		if quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return quality
+		}
+	}
+	return 1
+}
+
+func (o *HTTPOptions) marshal(v interface{}) ([]byte, error) {
+	if o.Serializer != nil {
+		return o.Serializer.Marshal(v)
+	}
+	return o.marshalJSON(v)
+}
+
+// marshalJSON encodes v with Marshal, or with encoding/json when options are
+// built as a literal without one.
+func (o *HTTPOptions) marshalJSON(v interface{}) ([]byte, error) {
+	if o.Marshal == nil {
+		return json.Marshal(v)
+	}
+	return o.Marshal(v)
+}
+
+// MarshalXML sends values that encoding/xml cannot encode, such as maps, as
+// their JSON text.
+func (ve ValidationError) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain ValidationError
+	p := plain(ve)
+	p.Value = xmlValue(p.Value)
+	return e.EncodeElement(p, start)
+}
+
+// MarshalXML encodes the value like ValidationError.MarshalXML.
+func (fv FieldViolation) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain FieldViolation
+	p := plain(fv)
+	p.Value = xmlValue(p.Value)
+	return e.EncodeElement(p, start)
+}
+
+// UnmarshalXML reads the value as a string, since XML does not carry its type.
+func (ve *ValidationError) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain ValidationError
+	var p struct {
+		plain
+		Value *string `xml:"value"`
+	}
+	if err := d.DecodeElement(&p, &start); err != nil {
+		return err
+	}
+
+	*ve = ValidationError(p.plain)
+	ve.Value = xmlText(p.Value)
+	return nil
+}
+
+// UnmarshalXML reads the value like ValidationError.UnmarshalXML.
+func (fv *FieldViolation) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain FieldViolation
+	var p struct {
+		plain
+		Value *string `xml:"value"`
+	}
+	if err := d.DecodeElement(&p, &start); err != nil {
+		return err
+	}
+
+	*fv = FieldViolation(p.plain)
+	fv.Value = xmlText(p.Value)
+	return nil
+}
+
+func xmlText(text *string) any {
+	if text == nil {
+		return nil
+	}
+	return *text
+}
+
+func xmlValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	if _, err := xml.Marshal(v); err == nil {
+		return v
+	}
+	if text, err := json.Marshal(v); err == nil {
+		return string(text)
+	}
+	return fmt.Sprint(v)
+}
+
+type xmlFieldViolations struct {
+	Name       string           `xml:"name,attr"`
+	Violations []FieldViolation `xml:"violation"`
+}
+
+// MarshalXML writes one <field name="..."> element per field, sorted by name.
+func (f FieldViolations) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(f)) {
+		field := xmlFieldViolations{Name: name, Violations: f[name]}
+		if err := e.EncodeElement(field, xml.StartElement{Name: xml.Name{Local: "field"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (f *FieldViolations) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var fields struct {
+		Fields []xmlFieldViolations `xml:"field"`
+	}
+	if err := d.DecodeElement(&fields, &start); err != nil {
+		return err
+	}
+
+	*f = make(FieldViolations, len(fields.Fields))
+	for _, field := range fields.Fields {
+		(*f)[field.Name] = append((*f)[field.Name], field.Violations...)
+	}
+	return nil
+}
+
+// Encode marshals v for a response body and returns its content type, so
+// framework adapters write the same bytes as WriteHTTPError. If the Serializer
+// fails, e.g. the XMLSerializer on a map, v is encoded as JSON instead and the
+// returned content type says so.
+func (o *HTTPOptions) Encode(v interface{}) ([]byte, string, error) {
+	body, err := o.marshal(v)
+	if err != nil && o.Serializer != nil {
+		body, err = o.marshalJSON(v)
+		return body, MediaTypeJSON, err
+	}
+	return body, o.contentType(), err
+}
+
+func (o *HTTPOptions) contentType() string {
+	if o.Serializer != nil {
+		return o.Serializer.ContentType()
+	}
+	return MediaTypeJSON
+}
@@ -0,0 +1,87 @@
+package erz_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/intezya/erz"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNegotiateSerializer(t *testing.T) {
+	erz.RegisterSerializer(erz.MediaTypeXML, erz.XMLSerializer{})
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: erz.MediaTypeJSON},
+		{accept: "text/html", want: erz.MediaTypeJSON},
+		{accept: "application/xml", want: erz.MediaTypeXML},
+		{accept: "application/json;q=0.5, application/xml", want: erz.MediaTypeXML},
+		{accept: "application/xml;q=0.5, application/json", want: erz.MediaTypeJSON},
+		{accept: "application/xml;q=0", want: erz.MediaTypeJSON},
+		{accept: "application/xml;charset=utf-8;q=0", want: erz.MediaTypeJSON},
+		{accept: "application/xml;charset=utf-8;q=0.9, application/json;q=0.1", want: erz.MediaTypeXML},
+		{accept: "application/json;q=0.1, application/xml; charset=utf-8 ; Q=0.2", want: erz.MediaTypeXML},
+		{accept: "APPLICATION/XML", want: erz.MediaTypeXML},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.accept, func(t *testing.T) {
+				if got, _ := erz.NegotiateSerializer(tt.accept); got != tt.want {
+					t.Errorf("NegotiateSerializer(%q) = %q, want %q", tt.accept, got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func roundTripResponse(version string) *erz.HTTPResponse {
+	opts := erz.DefaultHTTPOptions()
+	opts.IncludeTimestamp = true
+	opts.EnvelopeVersion = version
+
+	return erz.Validation("invalid order").
+		WithReason("ORDER_INVALID").
+		WithHint("check the highlighted fields").
+		WithRequestID("req-1").
+		WithValidationError("items[0].price", "must be positive", "-1").
+		WithValidationError("email", "is required", nil).
+		WithTimestamp(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)).
+		ToHTTPResponse(opts)
+}
+
+func TestSerializerRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer erz.Serializer
+		unmarshal  func([]byte, any) error
+	}{
+		{"json", erz.JSONSerializer{}, json.Unmarshal},
+		{"xml", erz.XMLSerializer{}, xml.Unmarshal},
+	}
+
+	for _, tt := range tests {
+		for _, version := range []string{erz.EnvelopeV1, erz.EnvelopeV2} {
+			t.Run(tt.name+"/"+version, func(t *testing.T) {
+				want := roundTripResponse(version)
+				data, err := tt.serializer.Marshal(want)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var got erz.HTTPResponse
+				if err := tt.unmarshal(data, &got); err != nil {
+					t.Fatalf("unmarshal %s: %v", data, err)
+				}
+				got.XMLName = xml.Name{}
+				if !reflect.DeepEqual(&got, want) {
+					t.Errorf("round trip changed the response:\n got %+v\nwant %+v\n%s", got.Error, want.Error, data)
+				}
+			})
+		}
+	}
+}
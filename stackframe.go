@@ -1,45 +1,228 @@
 package erz
 
 import (
+	"math/rand/v2"
 	"runtime"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type StackFrame struct {
-	Function string `json:"function"`
-	File     string `json:"file"`
-	Line     int    `json:"line"`
+	Function string `json:"function" xml:"function"`
+	File     string `json:"file" xml:"file"`
+	Line     int    `json:"line" xml:"line"`
+	Package  string `json:"package,omitempty" xml:"package,omitempty"`
+	FullPath string `json:"full_path,omitempty" xml:"full_path,omitempty"`
+}
+
+const (
+	packagePath            = "github.com/intezya/erz"
+	defaultStackTraceDepth = 10
+)
+
+var stackTraceDepth atomic.Int32
+
+// SetStackTraceDepth sets the maximum number of frames captured for new
+// errors. Values below 1 restore the default of 10.
+func SetStackTraceDepth(depth int) {
+	if depth < 1 {
+		depth = defaultStackTraceDepth
+	}
+	stackTraceDepth.Store(int32(depth))
+}
+
+func getStackTraceDepth() int {
+	if depth := int(stackTraceDepth.Load()); depth > 0 {
+		return depth
+	}
+	return defaultStackTraceDepth
+}
+
+var stackTraceSampler atomic.Pointer[func() bool]
+
+// SetStackTraceSampler sets a function deciding whether constructors such as
+// New and Wrap capture a stack trace. Unsampled errors carry no stack trace.
+// Explicit calls to WithStackTrace always capture. Passing nil captures on
+// every call, which is the default.
+func SetStackTraceSampler(sampler func() bool) {
+	if sampler == nil {
+		stackTraceSampler.Store(nil)
+		return
+	}
+	stackTraceSampler.Store(&sampler)
+}
+
+// SetStackTraceSampleRate captures stack traces for the given fraction of
+// errors created by the constructors. A rate of 0 never captures, 1 or more
+// always captures.
+func SetStackTraceSampleRate(rate float64) {
+	switch {
+	case rate >= 1:
+		SetStackTraceSampler(nil)
+	case rate <= 0:
+		SetStackTraceSampler(func() bool { return false })
+	default:
+		SetStackTraceSampler(func() bool { return rand.Float64() < rate })
+	}
+}
+
+func sampledStack(skip int) *lazyStack {
+	if sampler := stackTraceSampler.Load(); sampler != nil && !(*sampler)() {
+		return nil
+	}
+	return captureLazyStack(skip + 1)
+}
+
+var stackTraceFullPaths atomic.Bool
+
+// SetStackTraceFullPaths enables capturing the package path and the full file
+// path of each frame, in addition to the short function and file names.
+func SetStackTraceFullPaths(enabled bool) {
+	stackTraceFullPaths.Store(enabled)
+}
+
+// newStackFrame builds a frame from a fully qualified function name and an
+// absolute file path.
+func newStackFrame(function, file string, line int) StackFrame {
+	frame := StackFrame{
+		Function: function,
+		File:     file,
+		Line:     line,
+	}
+
+	// The package path ends at the first dot after the last slash. Dots in
+	// the last path element are escaped as %2e in symbol names, e.g.
+	// "gopkg.in/yaml%2ev3.(*Decoder).Decode", so they don't end it early.
+	pkgEnd := len(function)
+	name := function
+	if idx := strings.LastIndex(function, "/"); idx != -1 {
+		name = function[idx+1:]
+	}
+	if idx := strings.Index(name, "."); idx != -1 {
+		pkgEnd = len(function) - len(name) + idx
+	}
+	frame.Function = unescapeSymbol(name)
+	if idx := strings.LastIndex(file, "/"); idx != -1 {
+		frame.File = file[idx+1:]
+	}
+
+	if stackTraceFullPaths.Load() {
+		frame.FullPath = file
+		frame.Package = unescapeSymbol(function[:pkgEnd])
+	}
+
+	return frame
+}
+
+func unescapeSymbol(name string) string {
+	return strings.ReplaceAll(name, "%2e", ".")
+}
+
+// isInternalFrame reports whether a frame belongs to the runtime or to erz
+// itself. Such frames are dropped so the top frame is the caller's code.
+func isInternalFrame(funcName string) bool {
+	return strings.HasPrefix(funcName, "runtime.") || strings.HasPrefix(funcName, packagePath+".")
+}
+
+// lazyStack holds raw program counters and symbolizes them into frames only
+// when the stack trace is first requested, which keeps error creation cheap.
+// It is shared between copies of an error, so resolution is guarded by once.
+type lazyStack struct {
+	pcs    []uintptr
+	once   sync.Once
+	frames []StackFrame
+}
+
+func (s *lazyStack) resolve() []StackFrame {
+	s.once.Do(
+		func() {
+			s.frames = framesFromPCs(s.pcs)
+		},
+	)
+	return s.frames
+}
+
+// stackTraceSlack is the number of extra program counters captured on top of
+// the configured depth, to make up for runtime and erz frames that are dropped.
+const stackTraceSlack = 8
+
+func captureLazyStack(skip int) *lazyStack {
+	pcs := make([]uintptr, getStackTraceDepth()+stackTraceSlack)
+	n := runtime.Callers(skip+1, pcs)
+	return &lazyStack{pcs: pcs[:n]}
 }
 
 func captureStackTrace(skip int) []StackFrame {
+	return captureLazyStack(skip + 1).resolve()
+}
+
+func framesFromPCs(pcs []uintptr) []StackFrame {
 	var frames []StackFrame
 
-	for i := skip; i < skip+10; i++ {
-		pc, file, line, ok := runtime.Caller(i)
-		if !ok {
+	depth := getStackTraceDepth()
+	callers := runtime.CallersFrames(pcs)
+	for len(frames) < depth {
+		frame, more := callers.Next()
+		if frame.PC == 0 && !more {
+			break
+		}
+
+		if !isInternalFrame(frame.Function) {
+			frames = append(frames, newStackFrame(frame.Function, frame.File, frame.Line))
+		}
+
+		if !more {
 			break
 		}
+	}
+
+	return frames
+}
+
+// capturePanicStackTrace must be called from a deferred function while the
+// goroutine is panicking. It returns the frames below the panic call, so the
+// top frame is the code that panicked rather than the recovery handler.
+func capturePanicStackTrace(skip int) []StackFrame {
+	frames := parseDebugStack(debug.Stack())
+
+	for i, frame := range frames {
+		if frame.Function == "panic" {
+			return frames[i+1:]
+		}
+	}
+
+	return captureStackTrace(skip + 1)
+}
+
+func parseDebugStack(stack []byte) []StackFrame {
+	var frames []StackFrame
+
+	lines := strings.Split(string(stack), "\n")
+	for i := 1; i+1 < len(lines); i += 2 {
+		funcName := lines[i]
+		if strings.HasPrefix(funcName, "created by ") {
+			continue
+		}
+		if idx := strings.LastIndex(funcName, "("); idx != -1 {
+			funcName = funcName[:idx]
+		}
+		if isInternalFrame(funcName) {
+			continue
+		}
 
-		fn := runtime.FuncForPC(pc)
-		var funcName string
-		if fn != nil {
-			funcName = fn.Name()
-			if idx := strings.LastIndex(funcName, "/"); idx != -1 {
-				funcName = funcName[idx+1:]
-			}
+		location := strings.TrimSpace(lines[i+1])
+		if idx := strings.LastIndex(location, " +0x"); idx != -1 {
+			location = location[:idx]
 		}
 
-		if idx := strings.LastIndex(file, "/"); idx != -1 {
-			file = file[idx+1:]
+		file, line := location, 0
+		if idx := strings.LastIndex(location, ":"); idx != -1 {
+			file, line = location[:idx], parseInt(location[idx+1:])
 		}
 
-		frames = append(
-			frames, StackFrame{
-				Function: funcName,
-				File:     file,
-				Line:     line,
-			},
-		)
+		frames = append(frames, newStackFrame(funcName, file, line))
 	}
 
 	return frames
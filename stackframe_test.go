@@ -0,0 +1,91 @@
+package erz_test
+
+import (
+	"github.com/intezya/erz"
+	"strings"
+	"testing"
+)
+
+var benchErr erz.Error
+
+// BenchmarkNew creates errors without reading the stack trace, so only the
+// program counters are captured.
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = erz.New(erz.CodeInternal, "internal error")
+	}
+}
+
+// BenchmarkNewResolvedStack reads the stack trace of every error, which is the
+// cost eager symbolization paid on creation.
+func BenchmarkNewResolvedStack(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = erz.New(erz.CodeInternal, "internal error")
+		_ = benchErr.GetStackTrace()
+	}
+}
+
+func BenchmarkNewUnsampled(b *testing.B) {
+	erz.SetStackTraceSampleRate(0)
+	defer erz.SetStackTraceSampleRate(1)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = erz.New(erz.CodeInternal, "internal error")
+	}
+}
+
+func TestStackTraceSampling(t *testing.T) {
+	erz.SetStackTraceSampleRate(0)
+	defer erz.SetStackTraceSampleRate(1)
+
+	if stack := erz.New(erz.CodeInternal, "internal error").GetStackTrace(); len(stack) != 0 {
+		t.Errorf("unsampled error has %d frames", len(stack))
+	}
+	if stack := erz.New(erz.CodeInternal, "internal error").WithStackTrace().GetStackTrace(); len(stack) == 0 {
+		t.Error("WithStackTrace did not capture an unsampled error")
+	}
+}
+
+func TestStackTraceStartsAtCaller(t *testing.T) {
+	stack := erz.New(erz.CodeInternal, "internal error").GetStackTrace()
+	if len(stack) == 0 {
+		t.Fatal("no stack trace captured")
+	}
+	if want := "TestStackTraceStartsAtCaller"; !strings.HasSuffix(stack[0].Function, want) {
+		t.Errorf("first frame is %q, want %s", stack[0].Function, want)
+	}
+}
+
+func TestStackFramePackage(t *testing.T) {
+	erz.SetStackTraceFullPaths(true)
+	defer erz.SetStackTraceFullPaths(false)
+
+	tests := []struct {
+		function    string
+		wantPackage string
+		wantFunc    string
+	}{
+		{"github.com/intezya/erz.New", "github.com/intezya/erz", "erz.New"},
+		{"github.com/acme/app/users.(*Service).Get.func1", "github.com/acme/app/users", "users.(*Service).Get.func1"},
+		{"gopkg.in/yaml%2ev3.(*Decoder).Decode", "gopkg.in/yaml.v3", "yaml.v3.(*Decoder).Decode"},
+		{"main.main", "main", "main.main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			frame := erz.NewStackFrame(tt.function, "/src/app/file.go", 7)
+			if frame.Package != tt.wantPackage {
+				t.Errorf("package %q, want %q", frame.Package, tt.wantPackage)
+			}
+			if frame.Function != tt.wantFunc {
+				t.Errorf("function %q, want %q", frame.Function, tt.wantFunc)
+			}
+			if frame.File != "file.go" || frame.FullPath != "/src/app/file.go" {
+				t.Errorf("file %q, full path %q", frame.File, frame.FullPath)
+			}
+		})
+	}
+}
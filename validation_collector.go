@@ -1,11 +1,94 @@
 package erz
 
+import (
+	"errors"
+	"sort"
+)
+
 func ValidationWithErrors(message string, validationErrors []ValidationError) Error {
-	return &Er{
-		errCode:          CodeValidation,
-		message:          message,
-		validationErrors: validationErrors,
+	return observe(
+		&Er{
+			errCode:          CodeValidation,
+			message:          message,
+			validationErrors: appendValidationErrors(nil, validationErrors...),
+		},
+	)
+}
+
+// AddTypedValidation is a type-safe form of Error.WithValidationError. If err
+// is nil a new validation error is created.
+func AddTypedValidation[T any](err Error, field, message string, value T) Error {
+	if err == nil {
+		return FieldError(field, message, value)
 	}
+	return err.WithValidationError(field, message, value)
+}
+
+// MergeValidation combines the validation errors of errs into a single
+// CodeValidation error, dropping duplicate field and message pairs. It returns
+// nil if none of errs carries validation errors.
+func MergeValidation(errs ...error) Error {
+	merged := mergeValidationErrors(errs)
+	if len(merged) == 0 {
+		return nil
+	}
+	return ValidationWithErrors("validation failed", merged)
+}
+
+func mergeValidationErrors(errs []error) []ValidationError {
+	type fieldMessage struct {
+		field   string
+		message string
+	}
+
+	seen := make(map[fieldMessage]struct{})
+	var merged []ValidationError
+
+	for _, err := range errs {
+		var erzErr Error
+		if !errors.As(err, &erzErr) {
+			continue
+		}
+
+		for _, ve := range erzErr.GetValidationErrors() {
+			// Truncation markers are summed by appendValidationErrors, so two
+			// equal counts are not duplicates.
+			if !isTruncationMarker(ve) {
+				key := fieldMessage{field: ve.Field, message: ve.Message}
+				if _, exists := seen[key]; exists {
+					continue
+				}
+				seen[key] = struct{}{}
+			}
+			merged = appendValidationErrors(merged, ve)
+		}
+	}
+
+	return merged
+}
+
+// SortValidationErrors returns a copy of errs ordered by field and then
+// message, for deterministic output in snapshots and logs. The entry counting
+// errors dropped by the SetMaxValidationErrors limit stays last.
+func SortValidationErrors(errs []ValidationError) []ValidationError {
+	if len(errs) < 2 {
+		return errs
+	}
+
+	sorted := make([]ValidationError, len(errs))
+	copy(sorted, errs)
+	sort.SliceStable(
+		sorted, func(i, j int) bool {
+			if iMarker, jMarker := isTruncationMarker(sorted[i]), isTruncationMarker(sorted[j]); iMarker != jMarker {
+				return jMarker
+			}
+			if sorted[i].Field != sorted[j].Field {
+				return sorted[i].Field < sorted[j].Field
+			}
+			return sorted[i].Message < sorted[j].Message
+		},
+	)
+	return sorted
 }
 
 func CollectValidationErrors() *ValidationCollector {
@@ -15,12 +98,53 @@ func CollectValidationErrors() *ValidationCollector {
 }
 
 type ValidationCollector struct {
-	errors []ValidationError
+	errors    []ValidationError
+	root      *ValidationCollector
+	namespace string
+}
+
+// WithNamespace returns a view of the collector that prefixes every field it
+// adds with ns (e.g. "user" and "email" become "user.email"). Namespaces nest,
+// and errors added through the view are collected by the original collector.
+func (vc *ValidationCollector) WithNamespace(ns string) *ValidationCollector {
+	return &ValidationCollector{
+		root:      vc.target(),
+		namespace: vc.qualify(ns),
+	}
+}
+
+// WithIndex returns a view of the collector for element i of the current
+// namespace, e.g. WithNamespace("items").WithIndex(3) adds fields as
+// "items[3].price".
+func (vc *ValidationCollector) WithIndex(i int) *ValidationCollector {
+	return &ValidationCollector{
+		root:      vc.target(),
+		namespace: vc.qualify(FieldPath(i)),
+	}
+}
+
+func (vc *ValidationCollector) target() *ValidationCollector {
+	if vc.root != nil {
+		return vc.root
+	}
+	return vc
+}
+
+func (vc *ValidationCollector) qualify(field string) string {
+	return joinFieldPath(vc.namespace, field)
+}
+
+func (vc *ValidationCollector) add(ve ValidationError) {
+	root := vc.target()
+	if !isTruncationMarker(ve) {
+		ve.Field = vc.qualify(ve.Field)
+	}
+	root.errors = appendValidationErrors(root.errors, ve)
 }
 
 func (vc *ValidationCollector) Add(field, message string, value any) *ValidationCollector {
-	vc.errors = append(
-		vc.errors, ValidationError{
+	vc.add(
+		ValidationError{
 			Field:   field,
 			Message: message,
 			Value:   value,
@@ -29,17 +153,54 @@ func (vc *ValidationCollector) Add(field, message string, value any) *Validation
 	return vc
 }
 
+// AddWithCode is like Add with a machine-readable code for the violation,
+// such as "required" or "too_long".
+func (vc *ValidationCollector) AddWithCode(field, code, message string, value any) *ValidationCollector {
+	vc.add(
+		ValidationError{
+			Field:   field,
+			Code:    code,
+			Message: message,
+			Value:   value,
+		},
+	)
+	return vc
+}
+
+func (vc *ValidationCollector) AddIf(cond bool, field, message string, value any) *ValidationCollector {
+	if cond {
+		vc.Add(field, message, value)
+	}
+	return vc
+}
+
+// AddNested merges the errors of sub, prefixing each field with prefix
+// (e.g. "address" and "city" become "address.city").
+func (vc *ValidationCollector) AddNested(prefix string, sub *ValidationCollector) *ValidationCollector {
+	if sub == nil {
+		return vc
+	}
+
+	for _, ve := range sub.Errors() {
+		if !isTruncationMarker(ve) {
+			ve.Field = joinFieldPath(prefix, ve.Field)
+		}
+		vc.add(ve)
+	}
+	return vc
+}
+
 func (vc *ValidationCollector) HasErrors() bool {
-	return len(vc.errors) > 0
+	return len(vc.target().errors) > 0
 }
 
 func (vc *ValidationCollector) Error() Error {
 	if !vc.HasErrors() {
 		return nil
 	}
-	return ValidationWithErrors("validation failed", vc.errors)
+	return ValidationWithErrors("validation failed", vc.target().errors)
 }
 
 func (vc *ValidationCollector) Errors() []ValidationError {
-	return vc.errors
+	return vc.target().errors
 }
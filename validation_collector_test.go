@@ -0,0 +1,88 @@
+package erz_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/intezya/erz"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"slices"
+	"testing"
+)
+
+func TestValidationCollectorNestedNamespaces(t *testing.T) {
+	vc := erz.CollectValidationErrors()
+	user := vc.WithNamespace("user")
+	user.Add("email", "is required", nil)
+	user.WithNamespace("address").Add("city", "is required", nil)
+	vc.WithNamespace("items").WithIndex(3).Add("price", "must be positive", -1)
+
+	err := vc.Error()
+
+	wantFields := []string{"user.email", "user.address.city", "items[3].price"}
+	var fields []string
+	for _, ve := range err.GetValidationErrors() {
+		fields = append(fields, ve.Field)
+	}
+	if !slices.Equal(fields, wantFields) {
+		t.Errorf("fields %q, want %q", fields, wantFields)
+	}
+
+	want := "validation failed: user.email: is required; user.address.city: is required; items[3].price: must be positive"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	var grpcFields []string
+	for _, detail := range err.GRPCStatus().Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range br.GetFieldViolations() {
+				grpcFields = append(grpcFields, violation.GetField())
+			}
+		}
+	}
+	if !slices.Equal(grpcFields, wantFields) {
+		t.Errorf("BadRequest fields %q, want %q", grpcFields, wantFields)
+	}
+}
+
+func TestErrorWithoutValidationErrors(t *testing.T) {
+	if got := erz.NotFound("user").Error(); got != "user not found" {
+		t.Errorf("Error() = %q", got)
+	}
+	if got := erz.New(erz.CodeInternal, "").Error(); got != string(erz.CodeInternal) {
+		t.Errorf("Error() = %q, want the code", got)
+	}
+}
+
+func TestMergeValidationDedup(t *testing.T) {
+	structErrs := erz.Validation("invalid request").
+		WithValidationError("email", "is required", nil).
+		WithValidationError("age", "must be positive", -1)
+	ruleErrs := erz.Validation("business rules failed").
+		WithValidationError("email", "is required", "").
+		WithValidationError("email", "is already taken", "a@example.com").
+		WithValidationError("age", "must be positive", -1)
+
+	err := erz.MergeValidation(structErrs, fmt.Errorf("rules: %w", ruleErrs), errors.New("not a validation error"), nil)
+	if err == nil {
+		t.Fatal("MergeValidation returned nil")
+	}
+	if err.Code() != erz.CodeValidation {
+		t.Errorf("code %s, want %s", err.Code(), erz.CodeValidation)
+	}
+
+	want := []string{"email: is required", "age: must be positive", "email: is already taken"}
+	var got []string
+	for _, ve := range err.GetValidationErrors() {
+		got = append(got, ve.Field+": "+ve.Message)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("merged %q, want %q", got, want)
+	}
+}
+
+func TestMergeValidationWithoutValidationErrors(t *testing.T) {
+	if err := erz.MergeValidation(erz.NotFound("user"), errors.New("boom"), nil); err != nil {
+		t.Errorf("MergeValidation = %v, want nil", err)
+	}
+}
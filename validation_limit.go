@@ -0,0 +1,72 @@
+package erz
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ValidationTruncatedCode is the code of the entry that replaces validation
+// errors dropped because of the SetMaxValidationErrors limit. Its value is the
+// number of dropped errors.
+const ValidationTruncatedCode = "truncated"
+
+const defaultMaxValidationErrors = 1000
+
+var maxValidationErrors atomic.Int64
+
+func init() {
+	maxValidationErrors.Store(defaultMaxValidationErrors)
+}
+
+// SetMaxValidationErrors limits how many validation errors WithValidationError,
+// WithValidationErrors, ValidationWithErrors, MergeValidation, ErrorGroup and
+// ValidationCollector keep. Further errors are dropped and counted in a single
+// "... and N more" entry with ValidationTruncatedCode. The default is 1000;
+// n <= 0 disables the limit.
+func SetMaxValidationErrors(n int) {
+	maxValidationErrors.Store(int64(n))
+}
+
+func appendValidationErrors(errs []ValidationError, add ...ValidationError) []ValidationError {
+	limit := int(maxValidationErrors.Load())
+	if limit <= 0 {
+		return append(errs, add...)
+	}
+
+	dropped := 0
+	if n := len(errs); n > 0 && isTruncationMarker(errs[n-1]) {
+		dropped = truncatedCount(errs[n-1])
+		errs = errs[:n-1]
+	}
+
+	for _, ve := range add {
+		switch {
+		case isTruncationMarker(ve):
+			dropped += truncatedCount(ve)
+		case len(errs) < limit:
+			errs = append(errs, ve)
+		default:
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		errs = append(
+			errs, ValidationError{
+				Code:    ValidationTruncatedCode,
+				Message: fmt.Sprintf("... and %d more", dropped),
+				Value:   dropped,
+			},
+		)
+	}
+	return errs
+}
+
+func isTruncationMarker(ve ValidationError) bool {
+	return ve.Field == "" && ve.Code == ValidationTruncatedCode
+}
+
+func truncatedCount(marker ValidationError) int {
+	count, _ := marker.Value.(int)
+	return count
+}
@@ -0,0 +1,75 @@
+package erz_test
+
+import (
+	"github.com/intezya/erz"
+	"strconv"
+	"testing"
+)
+
+func validationErrors(n int) []erz.ValidationError {
+	errs := make([]erz.ValidationError, n)
+	for i := range errs {
+		errs[i] = erz.ValidationError{Field: "items[" + strconv.Itoa(i) + "]", Message: "is invalid"}
+	}
+	return errs
+}
+
+func assertTruncated(t *testing.T, errs []erz.ValidationError, kept, dropped int) {
+	t.Helper()
+
+	if len(errs) != kept+1 {
+		t.Fatalf("got %d validation errors, want %d and a marker", len(errs), kept)
+	}
+	marker := errs[len(errs)-1]
+	if marker.Code != erz.ValidationTruncatedCode || marker.Value != dropped {
+		t.Errorf("marker %+v, want code %q and %d dropped", marker, erz.ValidationTruncatedCode, dropped)
+	}
+	if want := "... and " + strconv.Itoa(dropped) + " more"; marker.Message != want {
+		t.Errorf("marker message %q, want %q", marker.Message, want)
+	}
+}
+
+func TestMaxValidationErrors(t *testing.T) {
+	erz.SetMaxValidationErrors(3)
+	defer erz.SetMaxValidationErrors(1000)
+
+	t.Run(
+		"WithValidationErrors", func(t *testing.T) {
+			err := erz.Validation("invalid").WithValidationErrors(validationErrors(5)...)
+			assertTruncated(t, err.GetValidationErrors(), 3, 2)
+		},
+	)
+	t.Run(
+		"WithValidationError", func(t *testing.T) {
+			err := erz.Validation("invalid")
+			for i := 0; i < 5; i++ {
+				err = err.WithValidationError("field"+strconv.Itoa(i), "is invalid", nil)
+			}
+			assertTruncated(t, err.GetValidationErrors(), 3, 2)
+		},
+	)
+	t.Run(
+		"ValidationWithErrors", func(t *testing.T) {
+			err := erz.ValidationWithErrors("invalid", validationErrors(5))
+			assertTruncated(t, err.GetValidationErrors(), 3, 2)
+		},
+	)
+	t.Run(
+		"collector", func(t *testing.T) {
+			collector := erz.CollectValidationErrors()
+			for _, ve := range validationErrors(5) {
+				collector.Add(ve.Field, ve.Message, nil)
+			}
+			assertTruncated(t, collector.Error().GetValidationErrors(), 3, 2)
+		},
+	)
+	t.Run(
+		"MergeValidation", func(t *testing.T) {
+			first := erz.ValidationWithErrors("invalid", validationErrors(5))
+			second := erz.ValidationWithErrors("invalid", validationErrors(10)[5:])
+			// Both inputs carry "... and 2 more", and the 3 errors kept by the
+			// second one fall over the limit while merging.
+			assertTruncated(t, erz.MergeValidation(first, second).GetValidationErrors(), 3, 7)
+		},
+	)
+}
@@ -0,0 +1,37 @@
+package erz
+
+const WSMessageTypeError = "error"
+
+type WSWriter interface {
+	WriteMessage(data []byte) error
+}
+
+type WSMessage struct {
+	Type string `json:"type"`
+	*HTTPResponse
+}
+
+func (e *Er) ToWSMessage() ([]byte, error) {
+	if e == nil {
+		return errNilPassed().ToWSMessage()
+	}
+
+	options := DefaultHTTPOptions()
+	message := &WSMessage{
+		Type:         WSMessageTypeError,
+		HTTPResponse: e.ToHTTPResponse(options),
+	}
+
+	return options.marshalJSON(message)
+}
+
+func WriteWSError(conn WSWriter, err Error) error {
+	err = orNilPassed(err)
+
+	data, marshalErr := err.ToWSMessage()
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	return conn.WriteMessage(data)
+}